@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/StevenBlack/ghosts/hosts"
+)
+
+// Exit codes of the ghosts command, one for each class of failure.
+const (
+	ExitOK       = 0 // success
+	ExitError    = 1 // any failure not covered below
+	ExitUsage    = 2 // bad command line flags or arguments
+	ExitNotFound = 3 // a file or URL does not exist
+	ExitNetwork  = 4 // a URL could not be fetched
+	ExitDecode   = 5 // a source is binary rather than text
+	ExitEmpty    = 6 // a source holds no domains
+	ExitMismatch = 7 // a source does not match its lockfile pin
+
+	ExitCanceled = 130 // interrupted, or the -timeout deadline passed
+)
+
+// exitCode maps an error to the exit code of its class.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, new(usageError)):
+		return ExitUsage
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return ExitCanceled
+	case errors.Is(err, hosts.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, hosts.ErrNetwork):
+		return ExitNetwork
+	case errors.Is(err, hosts.ErrDecode):
+		return ExitDecode
+	case errors.Is(err, hosts.ErrEmpty):
+		return ExitEmpty
+	case errors.Is(err, hosts.ErrMismatch):
+		return ExitMismatch
+	}
+	return ExitError
+}
+
+// A usageError is a bad command line flag or argument.
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+
+func (e usageError) Unwrap() error { return e.err }
+
+// usagef formats a usage error.
+func usagef(format string, a ...interface{}) error {
+	return usageError{fmt.Errorf(format, a...)}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/StevenBlack/ghosts/hosts"
+)
+
+func TestExitCode(t *testing.T) {
+	// testing the exit code of each class of failure
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{usagef("-format %s: want text or json", "bogus"), ExitUsage},
+		{fmt.Errorf("wrapped: %w", usageError{errors.New("eval: missing )")}), ExitUsage},
+		{&hosts.LoadError{Location: "x", Kind: hosts.ErrNotFound}, ExitNotFound},
+		{&hosts.LoadError{Location: "x", Kind: hosts.ErrNetwork}, ExitNetwork},
+		{&hosts.LoadError{Location: "x", Kind: hosts.ErrDecode}, ExitDecode},
+		{&hosts.LoadError{Location: "x", Kind: hosts.ErrEmpty}, ExitEmpty},
+		{&hosts.LoadError{Location: "x", Err: context.Canceled}, ExitCanceled},
+		{&hosts.LoadError{Location: "x", Kind: hosts.ErrNetwork, Err: context.DeadlineExceeded}, ExitCanceled},
+		{fmt.Errorf("wrapped: %w", &hosts.LoadError{Location: "x", Kind: hosts.ErrMismatch}), ExitMismatch},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%v: got exit code %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,14 @@
-module ghosts
+module github.com/StevenBlack/ghosts
 
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/atotto/clipboard v0.1.4
 	github.com/dustin/go-humanize v1.0.0
 	github.com/fatih/color v1.10.0 // indirect
 	github.com/rakyll/gotest v0.0.5 // indirect
-	github.com/thoas/go-funk v0.8.0
-	golang.org/x/sys v0.0.0-20210420205809-ac73e9fd8988 // indirect
+	golang.org/x/net v0.11.0
+	golang.org/x/sys v0.9.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
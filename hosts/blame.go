@@ -0,0 +1,89 @@
+package hosts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A BlameEvent is a commit that added a domain to a list, or removed it.
+type BlameEvent struct {
+	Commit Commit
+	Added  bool
+}
+
+// Blame finds the commits, oldest first, that added a domain to the file of
+// a git location, or removed it. Membership is decided on the parsed domains
+// of each revision, so reformatting a line does not count as a change.
+func Blame(ctx context.Context, g GitSource, domain string) ([]BlameEvent, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	// Only commits whose diff touches a line mentioning the domain can
+	// change its membership, so let git find those candidates.
+	out, err := git(ctx, g.Repo, "log", "--reverse", "--regexp-ignore-case", "-G", regexp.QuoteMeta(domain), commitFormat, g.Rev, "--", g.Path)
+	if err != nil {
+		return nil, &LoadError{g.String(), ErrNotFound, err}
+	}
+
+	var events []BlameEvent
+	listed := false
+	for _, c := range parseCommits(out) {
+		h, err := loadCommit(ctx, g, c.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if now := h.Set().Contains(domain); now != listed {
+			events = append(events, BlameEvent{Commit: c, Added: now})
+			listed = now
+		}
+	}
+	return events, nil
+}
+
+// WriteBlame writes the blame of a domain, one event per line.
+func WriteBlame(w io.Writer, domain string, events []BlameEvent) error {
+	if len(events) == 0 {
+		_, err := fmt.Fprintln(w, domain+": never listed")
+		return err
+	}
+	lines := []string{}
+	for _, e := range events {
+		sign := "-"
+		if e.Added {
+			sign = "+"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s %s  %s", sign, e.Commit.Date.Format("2006-01-02"), e.Commit.Hash[:12], e.Commit.Author, e.Commit.Subject))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// blameJSON is the JSON layout of a BlameEvent.
+type blameJSON struct {
+	Action  string    `json:"action"`
+	Commit  string    `json:"commit"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+}
+
+// WriteBlameJSON writes the blame of a domain as a JSON document.
+func WriteBlameJSON(w io.Writer, domain string, events []BlameEvent) error {
+	out := struct {
+		Domain string      `json:"domain"`
+		Listed bool        `json:"listed"`
+		Events []blameJSON `json:"events"`
+	}{Domain: domain, Events: []blameJSON{}}
+	for _, e := range events {
+		action := "removed"
+		if e.Added {
+			action = "added"
+		}
+		out.Events = append(out.Events, blameJSON{action, e.Commit.Hash, e.Commit.Date, e.Commit.Author, e.Commit.Subject})
+		out.Listed = e.Added
+	}
+	return writeJSON(w, out)
+}
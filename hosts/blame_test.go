@@ -0,0 +1,57 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBlame(t *testing.T) {
+	// testing the commits that added and removed a domain
+	repo := gitRepo(t,
+		"0.0.0.0 ads.example.com\n0.0.0.0 bb.com\n",
+		"127.0.0.1 ads.example.com\n0.0.0.0 bb.com\n",
+		"# 0.0.0.0 ads.example.com\n0.0.0.0 bb.com\n",
+		"0.0.0.0 ADS.example.com\n0.0.0.0 bb.com\n",
+		"0.0.0.0 ads.example.com\n0.0.0.0 cc.com\n",
+	)
+
+	events, err := Blame(context.Background(), GitSource{Repo: repo, Rev: "HEAD", Path: "hosts"}, "ads.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		subject string
+		added   bool
+	}{{"version 1", true}, {"version 3", false}, {"version 4", true}}
+	if got, want := len(events), len(want); got != want {
+		t.Fatalf("got %d events, want %d", got, want)
+	}
+	for i, e := range events {
+		if e.Commit.Subject != want[i].subject || e.Added != want[i].added {
+			t.Errorf("event %d: got %q added %v, want %q added %v", i, e.Commit.Subject, e.Added, want[i].subject, want[i].added)
+		}
+	}
+
+	var text strings.Builder
+	WriteBlame(&text, "ads.example.com", events)
+	if got, want := strings.Count(text.String(), "\n"), 3; got != want {
+		t.Errorf("got %d lines, want %d", got, want)
+	}
+
+	var js strings.Builder
+	WriteBlameJSON(&js, "ads.example.com", events)
+	var decoded struct{ Listed bool }
+	if err := json.Unmarshal([]byte(js.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Listed {
+		t.Errorf("got listed false, want true")
+	}
+
+	events, _ = Blame(context.Background(), GitSource{Repo: repo, Rev: "HEAD", Path: "hosts"}, "never.example.com")
+	if got, want := len(events), 0; got != want {
+		t.Errorf("got %d events for an unlisted domain, want %d", got, want)
+	}
+}
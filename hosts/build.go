@@ -0,0 +1,223 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Extensions are the categories of the StevenBlack/hosts extensions, in the
+// order they appear in the names of the alternates.
+var Extensions = []string{"fakenews", "gambling", "porn", "social"}
+
+// UpdateInfo is the update.json metadata of a source in a data/ tree.
+type UpdateInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HomeURL     string `json:"homeurl"`
+	Frequency   string `json:"frequency"`
+	Issues      string `json:"issues"`
+	URL         string `json:"url"`
+	License     string `json:"license"`
+}
+
+// A BuildSource is one source of an amalgamated list.
+type BuildSource struct {
+	Dir       string     // the directory holding its hosts file
+	Extension string     // its extension, or "" for the base sources
+	Info      UpdateInfo // its update.json, if it has one
+}
+
+// BuildOptions control how an amalgamated list is built.
+type BuildOptions struct {
+	Extensions []string  // the extensions to add to the base sources
+	Whitelist  string    // a file of domains to leave out, with their subdomains; default <root>/whitelist
+	Date       time.Time // the date in the header; default now
+	Options    Options   // how to process the built list
+}
+
+// BuildSources finds the sources of an amalgamated list in a StevenBlack-style
+// tree: data/<source>/hosts, and extensions/<extension>/hosts or
+// extensions/<extension>/<source>/hosts.
+func BuildSources(root string, extensions []string) ([]BuildSource, error) {
+	sources, err := findSources(filepath.Join(root, "data"), "")
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, &LoadError{root, ErrNotFound, errors.New("no data/<source>/hosts files")}
+	}
+	for _, ext := range extensions {
+		dir := filepath.Join(root, "extensions", ext)
+		found, err := findSources(dir, ext)
+		if err != nil {
+			return nil, err
+		}
+		if isFile(filepath.Join(dir, "hosts")) {
+			found = append([]BuildSource{newBuildSource(dir, ext)}, found...)
+		}
+		if len(found) == 0 {
+			return nil, &LoadError{dir, ErrNotFound, fmt.Errorf("no hosts files for the %s extension", ext)}
+		}
+		sources = append(sources, found...)
+	}
+	return sources, nil
+}
+
+// findSources returns the subdirectories of dir that hold a hosts file, by name.
+func findSources(dir, extension string) ([]BuildSource, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sources []BuildSource
+	for _, e := range entries {
+		sub := filepath.Join(dir, e.Name())
+		if e.IsDir() && isFile(filepath.Join(sub, "hosts")) {
+			sources = append(sources, newBuildSource(sub, extension))
+		}
+	}
+	return sources, nil
+}
+
+func newBuildSource(dir, extension string) BuildSource {
+	s := BuildSource{Dir: dir, Extension: extension}
+	if bytes, err := ioutil.ReadFile(filepath.Join(dir, "update.json")); err == nil {
+		json.Unmarshal(bytes, &s.Info)
+	}
+	if s.Info.Name == "" {
+		s.Info.Name = filepath.Base(dir)
+	}
+	return s
+}
+
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// Build amalgamates the sources of a StevenBlack-style tree, with the chosen
+// extensions, into one list with a generated header. Whitelisted domains,
+// and their subdomains, are left out.
+func Build(ctx context.Context, root string, o BuildOptions) (*Hosts, error) {
+	sources, err := BuildSources(root, o.Extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	whitelist := o.Whitelist
+	if whitelist == "" && isFile(filepath.Join(root, "whitelist")) {
+		whitelist = filepath.Join(root, "whitelist")
+	}
+	allowed := DomainSet{}
+	if whitelist != "" {
+		w := New(Options{})
+		if err := w.Load(ctx, whitelist); err != nil && !errors.Is(err, ErrEmpty) {
+			return nil, err
+		}
+		allowed = w.Set()
+	}
+
+	// merge the sources in order, keeping the first of each domain
+	seen := map[string]bool{}
+	var domains []string
+	for _, s := range sources {
+		h := New(Options{})
+		if err := h.Load(ctx, filepath.Join(s.Dir, "hosts")); err != nil && !errors.Is(err, ErrEmpty) {
+			return nil, err
+		}
+		for _, d := range h.Domains() {
+			if !seen[d] && !whitelisted(d, allowed) {
+				seen[d] = true
+				domains = append(domains, d)
+			}
+		}
+	}
+
+	date := o.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	lines := buildHeader(o.Extensions, date.UTC(), len(domains))
+	for _, d := range domains {
+		lines = append(lines, "0.0.0.0 "+d)
+	}
+
+	h := New(o.Options)
+	err = h.LoadBytes(ctx, root, []byte(strings.Join(lines, "\n")+"\n"))
+	return h, err
+}
+
+// whitelisted reports whether a domain, or any of its parent domains, is allowed.
+func whitelisted(domain string, allowed DomainSet) bool {
+	for {
+		if allowed.Contains(domain) {
+			return true
+		}
+		i := strings.Index(domain, ".")
+		if i < 0 {
+			return false
+		}
+		domain = domain[i+1:]
+	}
+}
+
+// buildHeader writes the header of an amalgamated list, after the fashion of
+// the StevenBlack/hosts builds.
+func buildHeader(extensions []string, date time.Time, count int) []string {
+	title := "StevenBlack/hosts"
+	fetch := "https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts"
+	exts := append([]string{}, extensions...)
+	sort.SliceStable(exts, func(i, j int) bool { return extensionOrder(exts[i]) < extensionOrder(exts[j]) })
+	if len(exts) > 0 {
+		named := strings.Join(exts, ", ")
+		if len(exts) > 1 {
+			named = strings.Join(exts[:len(exts)-1], ", ") + " and " + exts[len(exts)-1]
+		}
+		title += " with the " + named + " extensions"
+		fetch = "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/" + strings.Join(exts, "-") + "/hosts"
+	}
+	header := []string{
+		"# Title: " + title,
+		"#",
+		"# This hosts file is a merged collection of hosts from reputable sources,",
+		"# with a dash of crowd sourcing via GitHub",
+		"#",
+		"# Date: " + date.Format("2 January 2006 15:04:05 (MST)"),
+	}
+	if len(exts) > 0 {
+		header = append(header, "# Extensions added to this file: "+strings.Join(exts, ", "))
+	}
+	return append(header,
+		"# Number of unique domains: "+humanize.Comma(int64(count)),
+		"#",
+		"# Fetch the latest version of this file: "+fetch,
+		"# Project home page: https://github.com/StevenBlack/hosts",
+		"# Project releases: https://github.com/StevenBlack/hosts/releases",
+		"#",
+		"# ===============================================================",
+		"",
+	)
+}
+
+// extensionOrder ranks an extension among Extensions, with others after them.
+func extensionOrder(ext string) int {
+	for i, e := range Extensions {
+		if e == ext {
+			return i
+		}
+	}
+	return len(Extensions)
+}
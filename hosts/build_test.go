@@ -0,0 +1,79 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildBase(t *testing.T) {
+	// testing a build of the base sources, with the whitelist applied
+	hf, err := Build(context.Background(), "../test/build", BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := len(hf.Domains())
+	want := 3
+
+	if got != want {
+		t.Errorf("got %d domains, want %d: %v", got, want, hf.Domains())
+	}
+	for _, d := range hf.Domains() {
+		if strings.HasSuffix(d, "allowed.example.org") {
+			t.Errorf("got whitelisted domain %s", d)
+		}
+	}
+}
+
+func TestBuildExtensions(t *testing.T) {
+	// testing a build with extensions, and its generated header
+	date := time.Date(2026, 10, 19, 5, 11, 19, 0, time.UTC)
+	hf, err := Build(context.Background(), "../test/build", BuildOptions{Extensions: []string{"porn", "gambling"}, Date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := len(hf.Domains())
+	want := 6
+
+	if got != want {
+		t.Errorf("got %d domains, want %d: %v", got, want, hf.Domains())
+	}
+
+	header := strings.Join(hf.Header(), "\n")
+	for _, want := range []string{
+		"# Title: StevenBlack/hosts with the gambling and porn extensions",
+		"# Number of unique domains: 6",
+		"alternates/gambling-porn/hosts",
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("got header without %q:\n%s", want, header)
+		}
+	}
+	if !hf.HeaderDate().Equal(date) {
+		t.Errorf("got header date %v, want %v", hf.HeaderDate(), date)
+	}
+}
+
+func TestBuildSources(t *testing.T) {
+	// testing the discovery of sources and their update.json
+	sources, err := BuildSources("../test/build", []string{"gambling"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(sources), 3; got != want {
+		t.Fatalf("got %d sources, want %d", got, want)
+	}
+	if got, want := sources[2].Info.Name, "Sinfonietta Gambling"; got != want {
+		t.Errorf("got source name %q, want %q", got, want)
+	}
+
+	_, err = BuildSources("../test/build", []string{"crypto"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v for a missing extension, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,101 @@
+package hosts
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// A CoveredDomain is a domain blocked through one of its parent domains.
+type CoveredDomain struct {
+	Domain string `json:"domain"`
+	By     string `json:"by"` // the closest parent domain in the main list
+}
+
+// Coverage is how well a main list covers the domains of another list when
+// each of its domains blocks its subdomains too, as with wildcard blocking.
+type Coverage struct {
+	From, To  string          // the locations of the main and compared lists
+	Exact     []string        // domains in the main list
+	Covered   []CoveredDomain // domains with a parent domain in the main list
+	Uncovered []string        // domains with neither
+}
+
+// Coverage reports how the domains of another list are covered by this one,
+// exactly or through a parent domain. The domains of each part are in the
+// order of the other list.
+func (h *Hosts) Coverage(other *Hosts) Coverage {
+	c := Coverage{From: h.location, To: other.location, Exact: []string{}, Covered: []CoveredDomain{}, Uncovered: []string{}}
+	blocked := h.Set()
+	for _, d := range other.domains {
+		if blocked.Contains(d) {
+			c.Exact = append(c.Exact, d)
+		} else if parent := coveredBy(d, blocked); len(parent) > 0 {
+			c.Covered = append(c.Covered, CoveredDomain{d, parent})
+		} else {
+			c.Uncovered = append(c.Uncovered, d)
+		}
+	}
+	return c
+}
+
+// coveredBy returns the closest parent of a domain that is blocked, or "".
+func coveredBy(domain string, blocked DomainSet) string {
+	for {
+		i := strings.Index(domain, ".")
+		if i < 0 {
+			return ""
+		}
+		domain = domain[i+1:]
+		if blocked.Contains(domain) {
+			return domain
+		}
+	}
+}
+
+// Write writes the counts of each part of the coverage, then the domains
+// covered by a parent, with that parent, and the uncovered domains. With
+// exact, the domains in the main list are written too.
+func (c Coverage) Write(w io.Writer, exact bool) error {
+	lines := []string{
+		"Exact: " + humanize.Comma(int64(len(c.Exact))) + " domains",
+		"Covered by a parent: " + humanize.Comma(int64(len(c.Covered))) + " domains",
+		"Uncovered: " + humanize.Comma(int64(len(c.Uncovered))) + " domains",
+	}
+	if exact && len(c.Exact) > 0 {
+		lines = append(lines, strings.Repeat("-", 40), "Exact:")
+		lines = append(lines, c.Exact...)
+	}
+	if len(c.Covered) > 0 {
+		lines = append(lines, strings.Repeat("-", 40), "Covered by a parent:")
+		for _, d := range c.Covered {
+			lines = append(lines, d.Domain+" ("+d.By+")")
+		}
+	}
+	if len(c.Uncovered) > 0 {
+		lines = append(lines, strings.Repeat("-", 40), "Uncovered:")
+		lines = append(lines, c.Uncovered...)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// WriteJSON writes the coverage as a JSON document, with counts and arrays.
+func (c Coverage) WriteJSON(w io.Writer) error {
+	out := struct {
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Exact     int    `json:"exact"`
+		Covered   int    `json:"covered"`
+		Uncovered int    `json:"uncovered"`
+		Domains   struct {
+			Exact     []string        `json:"exact"`
+			Covered   []CoveredDomain `json:"covered"`
+			Uncovered []string        `json:"uncovered"`
+		} `json:"domains"`
+	}{From: c.From, To: c.To, Exact: len(c.Exact), Covered: len(c.Covered), Uncovered: len(c.Uncovered)}
+	out.Domains.Exact, out.Domains.Covered, out.Domains.Uncovered = c.Exact, c.Covered, c.Uncovered
+	return writeJSON(w, out)
+}
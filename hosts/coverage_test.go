@@ -0,0 +1,46 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	// testing exact, parent-covered, and uncovered domains
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 foo.com\n0.0.0.0 ads.bar.com\n0.0.0.0 x.ads.bar.com\n"))
+	compare := New(Options{})
+	compare.LoadBytes(context.Background(), "compare", []byte("0.0.0.0 foo.com\n0.0.0.0 ads.foo.com\n0.0.0.0 y.x.ads.bar.com\n0.0.0.0 bar.com\n0.0.0.0 notfoo.com\n"))
+
+	c := main.Coverage(compare)
+	if got, want := strings.Join(c.Exact, " "), "foo.com"; got != want {
+		t.Errorf("got exact %q, want %q", got, want)
+	}
+	if got, want := len(c.Covered), 2; got != want {
+		t.Fatalf("got %d covered, want %d", got, want)
+	}
+	if got, want := c.Covered[1], (CoveredDomain{"y.x.ads.bar.com", "x.ads.bar.com"}); got != want {
+		t.Errorf("got %+v, want the closest parent %+v", got, want)
+	}
+	if got, want := strings.Join(c.Uncovered, " "), "bar.com notfoo.com"; got != want {
+		t.Errorf("got uncovered %q, want %q", got, want)
+	}
+
+	var out strings.Builder
+	c.Write(&out, false)
+	if !strings.Contains(out.String(), "\nads.foo.com (foo.com)\n") {
+		t.Errorf("got %q, want the covered domains with their parent", out.String())
+	}
+
+	out.Reset()
+	c.WriteJSON(&out)
+	var decoded struct{ Uncovered int }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Uncovered, 2; got != want {
+		t.Errorf("got %d uncovered in JSON, want %d", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package hosts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+)
+
+// A TallyDelta is how the tally of a TLD, or a root domain, changes from
+// one list to another.
+type TallyDelta struct {
+	Thing    string
+	Main     int // its tally in the main list
+	Compared int // its tally in the compared list
+}
+
+// Change is the absolute change of the tally.
+func (d TallyDelta) Change() int { return d.Compared - d.Main }
+
+// Percent is the change of the tally, as a percentage of the main tally. It
+// is +Inf for a thing found only in the compared list.
+func (d TallyDelta) Percent() float64 {
+	if d.Main == 0 {
+		return math.Inf(1)
+	}
+	return 100 * float64(d.Change()) / float64(d.Main)
+}
+
+// TLDDeltas lines up the TLD tallies of two lists.
+func (h *Hosts) TLDDeltas(other *Hosts) []TallyDelta {
+	return tallyDeltas(h.tlds, other.tlds)
+}
+
+// RootDeltas lines up the root domain tallies of two lists.
+func (h *Hosts) RootDeltas(other *Hosts) []TallyDelta {
+	return tallyDeltas(h.roots, other.roots)
+}
+
+// tallyDeltas returns the tallies that changed, the largest change first.
+func tallyDeltas(a, b map[string]int) []TallyDelta {
+	deltas := []TallyDelta{}
+	for thing, n := range a {
+		if len(thing) > 0 && b[thing] != n {
+			deltas = append(deltas, TallyDelta{thing, n, b[thing]})
+		}
+	}
+	for thing, n := range b {
+		if _, ok := a[thing]; len(thing) > 0 && !ok {
+			deltas = append(deltas, TallyDelta{thing, 0, n})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		c, d := abs(deltas[i].Change()), abs(deltas[j].Change())
+		if c != d {
+			return c > d
+		}
+		return deltas[i].Thing < deltas[j].Thing
+	})
+	return deltas
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// WriteTallyDeltas writes tally deltas as an aligned table, under a title
+// that counts them, with the top largest changes, or all of them when top
+// is 0. Things found in only one list are marked.
+func WriteTallyDeltas(w io.Writer, title string, deltas []TallyDelta, top int) error {
+	onlyMain, onlyCompared := 0, 0
+	for _, d := range deltas {
+		if d.Compared == 0 {
+			onlyMain++
+		} else if d.Main == 0 {
+			onlyCompared++
+		}
+	}
+	fmt.Fprintf(w, "%s delta: %s changed, %s only in the main list, %s only in the compared list\n", title,
+		humanize.Comma(int64(len(deltas))), humanize.Comma(int64(onlyMain)), humanize.Comma(int64(onlyCompared)))
+	if len(deltas) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tNAME\tMAIN\tCOMPARED\tCHANGE\t%")
+	for i, d := range deltas {
+		if top > 0 && i == top {
+			fmt.Fprintf(tw, "\t...\t%s more\n", humanize.Comma(int64(len(deltas)-top)))
+			break
+		}
+		mark, percent := " ", fmt.Sprintf("%+.1f%%", d.Percent())
+		switch {
+		case d.Main == 0:
+			mark, percent = "+", "only in the compared list"
+		case d.Compared == 0:
+			mark, percent = "-", "only in the main list"
+		}
+		change := humanize.Comma(int64(d.Change()))
+		if d.Change() > 0 {
+			change = "+" + change
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", mark, d.Thing, humanize.Comma(int64(d.Main)), humanize.Comma(int64(d.Compared)), change, percent)
+	}
+	return tw.Flush()
+}
+
+// WriteTallyDeltasCSV writes tally deltas as CSV rows of a kind of tally,
+// tld or root, with a header row when header is set. The percentage is
+// empty for a thing found only in the compared list.
+func WriteTallyDeltasCSV(w io.Writer, kind string, deltas []TallyDelta, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		cw.Write([]string{"tally", "thing", "main", "compared", "change", "percent"})
+	}
+	for _, d := range deltas {
+		percent := ""
+		if d.Main > 0 {
+			percent = strconv.FormatFloat(d.Percent(), 'f', 2, 64)
+		}
+		cw.Write([]string{kind, d.Thing, strconv.Itoa(d.Main), strconv.Itoa(d.Compared), strconv.Itoa(d.Change()), percent})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// deltaJSON is the JSON layout of a TallyDelta.
+type deltaJSON struct {
+	Thing    string   `json:"thing"`
+	Main     int      `json:"main"`
+	Compared int      `json:"compared"`
+	Change   int      `json:"change"`
+	Percent  *float64 `json:"percent"` // null when only in the compared list
+}
+
+// WriteTallyDeltasJSON writes tally deltas as a JSON document, an array of
+// deltas for each kind of tally.
+func WriteTallyDeltasJSON(w io.Writer, kinds map[string][]TallyDelta) error {
+	out := map[string][]deltaJSON{}
+	for kind, deltas := range kinds {
+		out[kind] = []deltaJSON{}
+		for _, d := range deltas {
+			j := deltaJSON{Thing: d.Thing, Main: d.Main, Compared: d.Compared, Change: d.Change()}
+			if d.Main > 0 {
+				p := d.Percent()
+				j.Percent = &p
+			}
+			out[kind] = append(out[kind], j)
+		}
+	}
+	return writeJSON(w, out)
+}
@@ -0,0 +1,60 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTallyDeltas(t *testing.T) {
+	// testing the changes of the TLD and root tallies between two lists
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 a.foo.com b.foo.com bar.net x.baz.org y.baz.org\n"))
+	compare := New(Options{})
+	compare.LoadBytes(context.Background(), "compare", []byte("0.0.0.0 z.foo.com y.bar.net w.bar.net v.bar.net x.baz.org y.baz.org qux.ru\n"))
+
+	deltas := main.TLDDeltas(compare)
+	want := []TallyDelta{{"net", 1, 3}, {"com", 2, 1}, {"ru", 0, 1}}
+	if got, want := len(deltas), len(want); got != want {
+		t.Fatalf("got %d TLD deltas, want %d", got, want)
+	}
+	for i := range want {
+		if deltas[i] != want[i] {
+			t.Errorf("delta %d: got %+v, want %+v", i, deltas[i], want[i])
+		}
+	}
+	if got, want := deltas[0].Percent(), 200.0; got != want {
+		t.Errorf("got %v%%, want %v%%", got, want)
+	}
+
+	deltas = main.RootDeltas(compare)
+	if got, want := len(deltas), 3; got != want {
+		t.Errorf("got %d root deltas, want %d", got, want)
+	}
+
+	var out strings.Builder
+	WriteTallyDeltas(&out, "TLD tally", main.TLDDeltas(compare), 0)
+	if !strings.HasPrefix(out.String(), "TLD tally delta: 3 changed, 0 only in the main list, 1 only in the compared list\n") {
+		t.Errorf("got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "+200.0%") || !strings.Contains(out.String(), "only in the compared list\n") {
+		t.Errorf("got %q, want the percentages, and the TLD only in the compared list marked", out.String())
+	}
+
+	out.Reset()
+	WriteTallyDeltasCSV(&out, "tld", main.TLDDeltas(compare), true)
+	if got, want := strings.Split(out.String(), "\n")[3], "tld,ru,0,1,1,"; got != want {
+		t.Errorf("got CSV row %q, want %q", got, want)
+	}
+
+	out.Reset()
+	WriteTallyDeltasJSON(&out, map[string][]TallyDelta{"tld": main.TLDDeltas(compare)})
+	var decoded map[string][]struct{ Percent *float64 }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["tld"][2].Percent != nil {
+		t.Errorf("got a percentage for a TLD only in the compared list, want null")
+	}
+}
@@ -0,0 +1,135 @@
+package hosts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/dustin/go-humanize"
+)
+
+// A Diff is how a compared list differs from a main list.
+type Diff struct {
+	From, To string   // the locations of the main and compared lists
+	Removed  []string // domains only in the main list
+	Added    []string // domains only in the compared list
+	Common   []string // domains in both lists
+}
+
+// Diff compares the domains of another list to those of this one.
+// The domains of each part are in alphabetical order.
+func (h *Hosts) Diff(other *Hosts) Diff {
+	a, b := h.Set(), other.Set()
+	return Diff{From: h.location, To: other.location, Removed: a.Difference(b), Added: b.Difference(a), Common: a.Intersection(b)}
+}
+
+// Select returns the diff with only the chosen parts; the others are emptied.
+func (d Diff) Select(removed, added, common bool) Diff {
+	if !removed {
+		d.Removed = []string{}
+	}
+	if !added {
+		d.Added = []string{}
+	}
+	if !common {
+		d.Common = []string{}
+	}
+	return d
+}
+
+// each calls f for every domain of the diff, in alphabetical order, with the
+// prefix of its part: - for Removed, + for Added, and a space for Common.
+func (d Diff) each(f func(prefix, domain string)) {
+	i, j, k := 0, 0, 0
+	for i < len(d.Removed) || j < len(d.Added) || k < len(d.Common) {
+		next, prefix := "", ""
+		if i < len(d.Removed) {
+			next, prefix = d.Removed[i], "-"
+		}
+		if j < len(d.Added) && (prefix == "" || d.Added[j] < next) {
+			next, prefix = d.Added[j], "+"
+		}
+		if k < len(d.Common) && (prefix == "" || d.Common[k] < next) {
+			next, prefix = d.Common[k], " "
+		}
+		switch prefix {
+		case "-":
+			i++
+		case "+":
+			j++
+		default:
+			k++
+		}
+		f(prefix, next)
+	}
+}
+
+// Write writes the diff in the style of a unified diff: a header with the
+// counts, then every domain in alphabetical order, prefixed with - when only
+// in the main list, + when only in the compared list, and a space when in both.
+// Without unchanged, the domains in both lists are left out.
+func (d Diff) Write(w io.Writer, unchanged bool) error {
+	lines := []string{
+		"--- " + d.From,
+		"+++ " + d.To,
+		fmt.Sprintf("@@ -%s +%s =%s @@",
+			humanize.Comma(int64(len(d.Removed))), humanize.Comma(int64(len(d.Added))), humanize.Comma(int64(len(d.Common)))),
+	}
+	d.each(func(prefix, domain string) {
+		if prefix != " " || unchanged {
+			lines = append(lines, prefix+domain)
+		}
+	})
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLines writes every domain of the diff, one per line, in alphabetical order.
+func (d Diff) WriteLines(w io.Writer) error {
+	var err error
+	d.each(func(prefix, domain string) {
+		if err == nil {
+			_, err = fmt.Fprintln(w, domain)
+		}
+	})
+	return err
+}
+
+// WriteCSV writes every domain of the diff as CSV, in alphabetical order,
+// with a header row and a column for its membership in each list: 1 if it
+// is in the list, and 0 if not.
+func (d Diff) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"domain", "main", "compared"})
+	membership := map[string][]string{"-": {"1", "0"}, "+": {"0", "1"}, " ": {"1", "1"}}
+	d.each(func(prefix, domain string) {
+		cw.Write(append([]string{domain}, membership[prefix]...))
+	})
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the diff as a JSON document, with counts and arrays.
+func (d Diff) WriteJSON(w io.Writer) error {
+	type part struct {
+		Count   int      `json:"count"`
+		Domains []string `json:"domains"`
+	}
+	out := struct {
+		From    string `json:"from"`
+		To      string `json:"to"`
+		Removed part   `json:"removed"`
+		Added   part   `json:"added"`
+		Common  part   `json:"common"`
+	}{
+		d.From, d.To,
+		part{len(d.Removed), d.Removed},
+		part{len(d.Added), d.Added},
+		part{len(d.Common), d.Common},
+	}
+	return writeJSON(w, out)
+}
@@ -0,0 +1,90 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	// testing the domains only in one list, or in both
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 zz.com\n0.0.0.0 aa.com\n0.0.0.0 bb.com\n"))
+	compare := New(Options{})
+	compare.Load(context.Background(), "../test/hosts-compare")
+
+	d := main.Diff(compare)
+	if got, want := strings.Join(d.Removed, " "), "zz.com"; got != want {
+		t.Errorf("got removed %q, want %q", got, want)
+	}
+	if got, want := strings.Join(d.Added, " "), "a_a.com b_b.com cc.com dd.com ee.com ff.com"; got != want {
+		t.Errorf("got added %q, want %q", got, want)
+	}
+	if got, want := strings.Join(d.Common, " "), "aa.com bb.com"; got != want {
+		t.Errorf("got common %q, want %q", got, want)
+	}
+
+	var out strings.Builder
+	d.Write(&out, false)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if got, want := lines[2], "@@ -1 +6 =2 @@"; got != want {
+		t.Errorf("got counts %q, want %q", got, want)
+	}
+	if got, want := len(lines), 3+7; got != want {
+		t.Errorf("got %d lines, want %d", got, want)
+	}
+	if got, want := lines[len(lines)-1], "-zz.com"; got != want {
+		t.Errorf("got last line %q, want %q", got, want)
+	}
+
+	out.Reset()
+	d.Write(&out, true)
+	if got, want := strings.Count(out.String(), "\n"), 3+9; got != want {
+		t.Errorf("got %d lines with the unchanged domains, want %d", got, want)
+	}
+	if !strings.Contains(out.String(), "\n+a_a.com\n aa.com\n") {
+		t.Errorf("got %q, want the domains in alphabetical order", out.String())
+	}
+
+	out.Reset()
+	d.WriteJSON(&out)
+	var decoded struct{ Added struct{ Count int } }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Added.Count, 6; got != want {
+		t.Errorf("got added count %d, want %d", got, want)
+	}
+}
+
+func TestDiffFormats(t *testing.T) {
+	// testing the newline and CSV formats, and the choice of parts
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 aa.com bb.com\n"))
+	compare := New(Options{})
+	compare.LoadBytes(context.Background(), "compare", []byte("0.0.0.0 bb.com cc.com\n"))
+	d := main.Diff(compare)
+
+	var out strings.Builder
+	d.WriteLines(&out)
+	if got, want := out.String(), "aa.com\nbb.com\ncc.com\n"; got != want {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+
+	out.Reset()
+	d.Select(false, true, false).WriteLines(&out)
+	if got, want := out.String(), "cc.com\n"; got != want {
+		t.Errorf("got the added lines %q, want %q", got, want)
+	}
+
+	out.Reset()
+	d.WriteCSV(&out)
+	if got, want := out.String(), "domain,main,compared\naa.com,1,0\nbb.com,1,1\ncc.com,0,1\n"; got != want {
+		t.Errorf("got CSV %q, want %q", got, want)
+	}
+
+	if got, want := len(d.Added), 1; got != want {
+		t.Errorf("got %d added after Select, want the diff itself left alone with %d", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package hosts
+
+import "sort"
+
+// A DomainSet is a set of domains, held as a sorted slice without duplicates,
+// so that set operations are a linear merge rather than a hash of every domain.
+type DomainSet []string
+
+// NewDomainSet returns the set of domains. Domains that are already sorted,
+// without duplicates, as those of a Hosts loaded without the Sort option are,
+// are used as is; others are copied first.
+func NewDomainSet(domains []string) DomainSet {
+	if isSet(domains) {
+		return DomainSet(domains)
+	}
+	s := append([]string{}, domains...)
+	sort.Strings(s)
+	// drop the duplicates
+	n := 0
+	for i := range s {
+		if i == 0 || s[i] != s[n-1] {
+			s[n] = s[i]
+			n++
+		}
+	}
+	return DomainSet(s[:n])
+}
+
+// isSet reports whether domains are sorted, without duplicates.
+func isSet(domains []string) bool {
+	for i := 1; i < len(domains); i++ {
+		if domains[i-1] >= domains[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Set returns the domains of the list as a DomainSet.
+func (h *Hosts) Set() DomainSet {
+	return NewDomainSet(h.domains)
+}
+
+// Contains reports whether a domain is in the set.
+func (s DomainSet) Contains(domain string) bool {
+	i := sort.SearchStrings(s, domain)
+	return i < len(s) && s[i] == domain
+}
+
+// Intersection returns the domains in both sets.
+func (s DomainSet) Intersection(t DomainSet) DomainSet {
+	n := len(s)
+	if len(t) < n {
+		n = len(t)
+	}
+	out := make(DomainSet, 0, n)
+	i, j := 0, 0
+	for i < len(s) && j < len(t) {
+		switch {
+		case s[i] < t[j]:
+			i++
+		case s[i] > t[j]:
+			j++
+		default:
+			out = append(out, s[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// IntersectionLen returns the number of domains in both sets, without
+// building their intersection.
+func (s DomainSet) IntersectionLen(t DomainSet) int {
+	n, i, j := 0, 0, 0
+	for i < len(s) && j < len(t) {
+		switch {
+		case s[i] < t[j]:
+			i++
+		case s[i] > t[j]:
+			j++
+		default:
+			n++
+			i++
+			j++
+		}
+	}
+	return n
+}
+
+// Union returns the domains in either set.
+func (s DomainSet) Union(t DomainSet) DomainSet {
+	out := make(DomainSet, 0, len(s)+len(t))
+	i, j := 0, 0
+	for i < len(s) && j < len(t) {
+		switch {
+		case s[i] < t[j]:
+			out = append(out, s[i])
+			i++
+		case s[i] > t[j]:
+			out = append(out, t[j])
+			j++
+		default:
+			out = append(out, s[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, s[i:]...)
+	return append(out, t[j:]...)
+}
+
+// Difference returns the domains of this set that are not in the other.
+func (s DomainSet) Difference(t DomainSet) DomainSet {
+	out := make(DomainSet, 0, len(s))
+	i, j := 0, 0
+	for i < len(s) && j < len(t) {
+		switch {
+		case s[i] < t[j]:
+			out = append(out, s[i])
+			i++
+		case s[i] > t[j]:
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return append(out, s[i:]...)
+}
@@ -0,0 +1,99 @@
+package hosts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDomainSet(t *testing.T) {
+	// testing the set operations on sorted domains
+	s := NewDomainSet([]string{"cc.com", "aa.com", "bb.com", "aa.com"})
+	u := NewDomainSet([]string{"bb.com", "dd.com", "cc.com"})
+
+	if got, want := strings.Join(s, " "), "aa.com bb.com cc.com"; got != want {
+		t.Errorf("got set %q, want %q", got, want)
+	}
+	if got, want := strings.Join(s.Intersection(u), " "), "bb.com cc.com"; got != want {
+		t.Errorf("got intersection %q, want %q", got, want)
+	}
+	if got, want := s.IntersectionLen(u), 2; got != want {
+		t.Errorf("got intersection length %d, want %d", got, want)
+	}
+	if got, want := strings.Join(s.Union(u), " "), "aa.com bb.com cc.com dd.com"; got != want {
+		t.Errorf("got union %q, want %q", got, want)
+	}
+	if got, want := strings.Join(s.Difference(u), " "), "aa.com"; got != want {
+		t.Errorf("got difference %q, want %q", got, want)
+	}
+	if got, want := strings.Join(u.Difference(s), " "), "dd.com"; got != want {
+		t.Errorf("got difference %q, want %q", got, want)
+	}
+	if !s.Contains("bb.com") || s.Contains("dd.com") {
+		t.Errorf("got Contains wrong for %q", strings.Join(s, " "))
+	}
+	if got, want := len(DomainSet{}.Union(nil)), 0; got != want {
+		t.Errorf("got %d domains in the union of empty sets, want %d", got, want)
+	}
+
+	// a sorted slice without duplicates is used as is
+	sorted := []string{"aa.com", "bb.com"}
+	if got := NewDomainSet(sorted); &got[0] != &sorted[0] {
+		t.Errorf("got a copy of a sorted slice, want the slice itself")
+	}
+}
+
+// benchmarkSets returns two sets of n domains that share half their domains.
+func benchmarkSets(n int) (DomainSet, DomainSet) {
+	a, b := make([]string, n), make([]string, n)
+	for i := 0; i < n; i++ {
+		a[i] = fmt.Sprintf("host%07d.example.com", i)
+		b[i] = fmt.Sprintf("host%07d.example.com", i+n/2)
+	}
+	return NewDomainSet(a), NewDomainSet(b)
+}
+
+func BenchmarkNewDomainSet(b *testing.B) {
+	s, _ := benchmarkSets(1000000)
+	shuffled := append([]string{}, s...)
+	for i := range shuffled {
+		j := (i * 7919) % len(shuffled)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewDomainSet(shuffled)
+	}
+}
+
+func BenchmarkIntersection(b *testing.B) {
+	s, t := benchmarkSets(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Intersection(t)
+	}
+}
+
+func BenchmarkIntersectionLen(b *testing.B) {
+	s, t := benchmarkSets(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.IntersectionLen(t)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	s, t := benchmarkSets(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Union(t)
+	}
+}
+
+func BenchmarkDifference(b *testing.B) {
+	s, t := benchmarkSets(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Difference(t)
+	}
+}
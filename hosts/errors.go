@@ -0,0 +1,43 @@
+package hosts
+
+import (
+	"bytes"
+	"errors"
+)
+
+// The classes of error a load can fail with. Test for them with errors.Is.
+var (
+	ErrNotFound = errors.New("hosts source not found")
+	ErrNetwork  = errors.New("network failure")
+	ErrDecode   = errors.New("hosts source is not text")
+	ErrEmpty    = errors.New("no domains in hosts source")
+	ErrMismatch = errors.New("hosts source does not match its pin")
+)
+
+// A LoadError records the location that failed to load, and why.
+type LoadError struct {
+	Location string
+	Kind     error
+	Err      error
+}
+
+func (e *LoadError) Error() string {
+	if e.Err == nil {
+		return e.Location + ": " + e.Kind.Error()
+	}
+	return e.Location + ": " + e.Err.Error()
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error belongs to the class target.
+func (e *LoadError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// isBinary reports whether raw bytes look like something other than text.
+func isBinary(raw []byte) bool {
+	return bytes.IndexByte(raw, 0) >= 0
+}
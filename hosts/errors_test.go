@@ -0,0 +1,51 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadErrors(t *testing.T) {
+	// testing the class of error returned by each kind of failed load
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			http.Error(w, "broken", http.StatusInternalServerError)
+		default:
+			w.Write([]byte("0.0.0.0 aa.com\n"))
+		}
+	}))
+	defer server.Close()
+
+	binary := filepath.Join(t.TempDir(), "hosts-binary")
+	os.WriteFile(binary, []byte{0x1f, 0x8b, 0x08, 0x00}, 0644)
+
+	tests := []struct {
+		location string
+		want     error
+	}{
+		{"../test/hosts-multi", nil},
+		{"../test/no-such-hosts", ErrNotFound},
+		{"../test/hosts-text", ErrEmpty},
+		{binary, ErrDecode},
+		{server.URL + "/hosts", nil},
+		{server.URL + "/missing", ErrNotFound},
+		{server.URL + "/broken", ErrNetwork},
+		{"http://127.0.0.1:1/hosts", ErrNetwork},
+	}
+
+	for _, tt := range tests {
+		hf := New(Options{})
+		err := hf.Load(context.Background(), tt.location)
+		if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("%s: got error %v, want %v", tt.location, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Eval evaluates a set expression over lists, and returns the resulting list.
+//
+// The operators are | for union, & for intersection, and - for difference,
+// with equal precedence, applied left to right; parentheses group. Operands
+// are locations, loaded with load, or filter predicates, the terms of a
+// Query, such as tld:ru or depth>=4. Intersecting a list with a predicate keeps the domains
+// that satisfy it, and subtracting one drops them. Quote an operand that
+// holds a space, a parenthesis, | or &, with ' or ". A lone - is stdin where
+// an operand is expected.
+//
+// When load is nil, locations are loaded with Load.
+func Eval(ctx context.Context, expr string, load func(ctx context.Context, location string) (*Hosts, error), o Options) (*Hosts, error) {
+	if load == nil {
+		load = func(ctx context.Context, location string) (*Hosts, error) {
+			h := New(Options{})
+			return h, h.Load(ctx, location)
+		}
+	}
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	e := &evaluator{ctx: ctx, load: load, tokens: tokens, loaded: map[string]DomainSet{}}
+	v, err := e.expression()
+	if err != nil {
+		return nil, err
+	}
+	if len(e.tokens) > 0 {
+		return nil, fmt.Errorf("eval: unexpected %q", e.tokens[0].text)
+	}
+	if v.pred != nil {
+		return nil, fmt.Errorf("eval: %s: a predicate alone is not a list", expr)
+	}
+
+	var lines []string
+	for _, d := range v.set {
+		lines = append(lines, "0.0.0.0 "+d)
+	}
+	// an empty result is an answer, not a failure
+	h := New(o)
+	if err = h.LoadBytes(ctx, expr, []byte(strings.Join(lines, "\n")+"\n")); errors.Is(err, ErrEmpty) {
+		return h, nil
+	}
+	return h, err
+}
+
+// A token of a set expression: an operator, a parenthesis, or an operand.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits a set expression into tokens.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.IndexByte("|&()", c) >= 0:
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(expr[i+1:], c)
+			if j < 0 {
+				return nil, fmt.Errorf("eval: unterminated %c", c)
+			}
+			tokens = append(tokens, token{text: expr[i+1 : i+1+j], quoted: true})
+			i += j + 2
+		default:
+			j := i
+			for j < len(expr) && strings.IndexByte(" \t\n|&()\"'", expr[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, token{text: expr[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// A value of a set expression: a set of domains, or a predicate on domains.
+type value struct {
+	set  DomainSet
+	pred func(domain string) bool
+}
+
+// An evaluator evaluates a set expression, token by token.
+type evaluator struct {
+	ctx    context.Context
+	load   func(ctx context.Context, location string) (*Hosts, error)
+	tokens []token
+	loaded map[string]DomainSet // the lists loaded so far, by location
+}
+
+// expression evaluates operands joined by operators, left to right.
+func (e *evaluator) expression() (value, error) {
+	v, err := e.operand()
+	if err != nil {
+		return value{}, err
+	}
+	for len(e.tokens) > 0 && isOperator(e.tokens[0]) {
+		op := e.tokens[0].text
+		e.tokens = e.tokens[1:]
+		w, err := e.operand()
+		if err != nil {
+			return value{}, err
+		}
+		if v, err = apply(op, v, w); err != nil {
+			return value{}, err
+		}
+	}
+	return v, nil
+}
+
+func isOperator(t token) bool {
+	return !t.quoted && (t.text == "|" || t.text == "&" || t.text == "-")
+}
+
+// operand evaluates a parenthesized expression, a predicate, or a list.
+func (e *evaluator) operand() (value, error) {
+	if len(e.tokens) == 0 {
+		return value{}, fmt.Errorf("eval: missing operand")
+	}
+	t := e.tokens[0]
+	e.tokens = e.tokens[1:]
+	switch {
+	case !t.quoted && t.text == "(":
+		v, err := e.expression()
+		if err != nil {
+			return value{}, err
+		}
+		if len(e.tokens) == 0 || e.tokens[0].quoted || e.tokens[0].text != ")" {
+			return value{}, fmt.Errorf("eval: missing )")
+		}
+		e.tokens = e.tokens[1:]
+		return v, nil
+	case !t.quoted && (t.text == ")" || t.text == "|" || t.text == "&"):
+		return value{}, fmt.Errorf("eval: unexpected %q", t.text)
+	}
+	if pred, ok, err := parseTerm(t.text); ok || err != nil {
+		return value{pred: pred}, err
+	}
+	if set, ok := e.loaded[t.text]; ok {
+		return value{set: set}, nil
+	}
+	// An empty list, like an allowlist with nothing in it yet, is an empty set.
+	h, err := e.load(e.ctx, t.text)
+	switch {
+	case errors.Is(err, ErrEmpty):
+		e.loaded[t.text] = NewDomainSet(nil)
+	case err != nil:
+		return value{}, err
+	default:
+		e.loaded[t.text] = h.Set()
+	}
+	return value{set: e.loaded[t.text]}, nil
+}
+
+// apply applies an operator to two values.
+func apply(op string, v, w value) (value, error) {
+	switch {
+	case v.pred == nil && w.pred == nil:
+		switch op {
+		case "|":
+			return value{set: v.set.Union(w.set)}, nil
+		case "&":
+			return value{set: v.set.Intersection(w.set)}, nil
+		}
+		return value{set: v.set.Difference(w.set)}, nil
+	case v.pred != nil && w.pred != nil:
+		p, q := v.pred, w.pred
+		switch op {
+		case "|":
+			return value{pred: func(d string) bool { return p(d) || q(d) }}, nil
+		case "&":
+			return value{pred: func(d string) bool { return p(d) && q(d) }}, nil
+		}
+		return value{pred: func(d string) bool { return p(d) && !q(d) }}, nil
+	case op == "&":
+		if v.pred != nil {
+			v, w = w, v
+		}
+		return value{set: filter(v.set, w.pred, true)}, nil
+	case op == "-" && w.pred != nil:
+		return value{set: filter(v.set, w.pred, false)}, nil
+	}
+	return value{}, fmt.Errorf("eval: a predicate can only be intersected with a list, or subtracted from one")
+}
+
+// filter returns the domains of a set that satisfy a predicate, or, without
+// keep, those that do not.
+func filter(s DomainSet, pred func(string) bool, keep bool) DomainSet {
+	out := DomainSet{}
+	for _, d := range s {
+		if pred(d) == keep {
+			out = append(out, d)
+		}
+	}
+	return out
+}
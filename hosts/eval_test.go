@@ -0,0 +1,64 @@
+package hosts
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	// testing set expressions over lists and predicates
+	lists := map[string]string{
+		"a":         "aa.com bb.com cc.ru dd.ru",
+		"b":         "bb.com ee.ru",
+		"allow.txt": "dd.ru",
+		"-":         "ff.com",
+		"empty.txt": "",
+	}
+	loads := 0
+	load := func(ctx context.Context, location string) (*Hosts, error) {
+		loads++
+		h := New(Options{})
+		return h, h.LoadBytes(ctx, location, []byte("0.0.0.0 "+lists[location]+"\n"))
+	}
+
+	for _, test := range []struct{ expr, want string }{
+		{"a | b", "aa.com bb.com cc.ru dd.ru ee.ru"},
+		{"a & b", "bb.com"},
+		{"a - b", "aa.com cc.ru dd.ru"},
+		{"(a | b) - allow.txt & tld:ru", "cc.ru ee.ru"},
+		{"a | (b - tld:ru)", "aa.com bb.com cc.ru dd.ru"},
+		{"tld:ru & a", "cc.ru dd.ru"},
+		{"a & (tld:com | root:cc.ru)", "aa.com bb.com cc.ru"},
+		{"a & 'b'", "bb.com"},
+		{"- | b", "bb.com ee.ru ff.com"},
+		{"a & b - b", ""},
+		{"a & len<6", "cc.ru dd.ru"},
+		{"b - empty.txt", "bb.com ee.ru"},
+		{"b & empty.txt", ""},
+	} {
+		h, err := Eval(context.Background(), test.expr, load, Options{})
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		if got := strings.Join(h.Domains(), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.expr, got, test.want)
+		}
+		if got, want := h.Location(), test.expr; got != want {
+			t.Errorf("got location %q, want %q", got, want)
+		}
+	}
+
+	loads = 0
+	Eval(context.Background(), "a | a & a", load, Options{})
+	if got, want := loads, 1; got != want {
+		t.Errorf("got %d loads of a list used thrice, want %d", got, want)
+	}
+
+	for _, expr := range []string{"", "a |", "(a | b", "a b", "tld:ru", "a | tld:ru", "tld:ru - a", "a & 'b"} {
+		if _, err := Eval(context.Background(), expr, load, Options{}); err == nil {
+			t.Errorf("%q: got no error, want one", expr)
+		}
+	}
+}
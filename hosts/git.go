@@ -0,0 +1,121 @@
+package hosts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("git", func(location string) (Source, error) { return ParseGitSource(location) })
+}
+
+// A GitSource reads a file from a local git repository, at any revision,
+// through the git binary. Its location is git:<repo>@<rev>:<path>, for
+// example git:/src/hosts@HEAD~10:hosts or git:/src/hosts@master@{1.month.ago}:hosts.
+// Without @<rev>, it reads HEAD.
+type GitSource struct {
+	Repo string
+	Rev  string
+	Path string
+}
+
+// ParseGitSource parses a git:<repo>@<rev>:<path> location.
+func ParseGitSource(location string) (GitSource, error) {
+	rest := strings.TrimPrefix(location, "git:")
+	i := strings.LastIndex(rest, ":")
+	if i < 0 || i == len(rest)-1 {
+		return GitSource{}, fmt.Errorf("%s: want git:<repo>@<rev>:<path>", location)
+	}
+	g := GitSource{Repo: rest[:i], Rev: "HEAD", Path: rest[i+1:]}
+	if j := strings.Index(g.Repo, "@"); j >= 0 {
+		g.Repo, g.Rev = g.Repo[:j], g.Repo[j+1:]
+	}
+	if g.Repo == "" || g.Rev == "" {
+		return GitSource{}, fmt.Errorf("%s: want git:<repo>@<rev>:<path>", location)
+	}
+	return g, nil
+}
+
+func (g GitSource) String() string {
+	return "git:" + g.Repo + "@" + g.Rev + ":" + g.Path
+}
+
+func (g GitSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	meta := Metadata{Location: g.String()}
+	commit, err := gitCommit(ctx, g.Repo, g.Rev)
+	if err != nil {
+		return nil, meta, &LoadError{meta.Location, ErrNotFound, err}
+	}
+	meta.Commit = commit.Hash
+	meta.Modified = commit.Date
+
+	blob, err := git(ctx, g.Repo, "cat-file", "blob", commit.Hash+":"+g.Path)
+	if err != nil {
+		return nil, meta, &LoadError{meta.Location, ErrNotFound, err}
+	}
+	return ioutil.NopCloser(bytes.NewReader(blob)), meta, nil
+}
+
+// A Commit is a commit of a git repository.
+type Commit struct {
+	Hash    string
+	Date    time.Time
+	Author  string
+	Subject string
+}
+
+// commitFormat is the git log format that parseCommits reads.
+const commitFormat = "--format=%H%x1f%cI%x1f%an <%ae>%x1f%s"
+
+// gitCommit resolves a revision to its commit.
+func gitCommit(ctx context.Context, repo, rev string) (Commit, error) {
+	out, err := git(ctx, repo, "log", "-1", commitFormat, rev+"^{commit}", "--")
+	if err != nil {
+		return Commit{}, err
+	}
+	commits := parseCommits(out)
+	if len(commits) == 0 {
+		return Commit{}, fmt.Errorf("%s: unknown revision", rev)
+	}
+	return commits[0], nil
+}
+
+// parseCommits reads the output of git log with commitFormat.
+func parseCommits(out []byte) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[1])
+		commits = append(commits, Commit{Hash: fields[0], Date: date, Author: fields[2], Subject: fields[3]})
+	}
+	return commits
+}
+
+// git runs a git command in a repository, and returns its output.
+func git(ctx context.Context, repo string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && stderr.Len() > 0 {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return out, nil
+}
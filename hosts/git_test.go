@@ -0,0 +1,101 @@
+package hosts
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo makes a repository whose hosts file goes through each of the
+// versions in turn, one commit per version, and returns its path.
+func gitRepo(t *testing.T, versions ...string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	date := ""
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	for i, v := range versions {
+		ioutil.WriteFile(filepath.Join(dir, "hosts"), []byte(v), 0644)
+		run("add", "hosts")
+		date = fmt.Sprintf("2026-10-%02dT12:00:00Z", i+1)
+		run("commit", "-q", "-m", fmt.Sprintf("version %d", i+1))
+	}
+	return dir
+}
+
+func TestParseGitSource(t *testing.T) {
+	// testing the parts of git locations
+	tests := map[string]GitSource{
+		"git:/src/hosts@HEAD~10:hosts":              {"/src/hosts", "HEAD~10", "hosts"},
+		"git:/src/hosts:alternates/porn/hosts":      {"/src/hosts", "HEAD", "alternates/porn/hosts"},
+		"git:/src/hosts@master@{1.month.ago}:hosts": {"/src/hosts", "master@{1.month.ago}", "hosts"},
+		"git:../relative@0a1b2c3:data/adaway/hosts": {"../relative", "0a1b2c3", "data/adaway/hosts"},
+	}
+	for location, want := range tests {
+		got, err := ParseGitSource(location)
+		if err != nil || got != want {
+			t.Errorf("%s: got %+v, %v, want %+v", location, got, err, want)
+		}
+	}
+
+	for _, location := range []string{"git:/src/hosts", "git:/src/hosts:", "git:@HEAD:hosts"} {
+		if _, err := ParseGitSource(location); err == nil {
+			t.Errorf("%s: got no error", location)
+		}
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	// testing hosts read from a git repository at two revisions
+	repo := gitRepo(t, "0.0.0.0 aa.com\n", "0.0.0.0 aa.com\n0.0.0.0 bb.com\n")
+
+	old := New(Options{})
+	if err := old.Load(context.Background(), "git:"+repo+"@HEAD~1:hosts"); err != nil {
+		t.Fatal(err)
+	}
+	now := New(Options{})
+	if err := now.Load(context.Background(), "git:"+repo+":hosts"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(old.Domains()), 1; got != want {
+		t.Errorf("got %d domains at HEAD~1, want %d", got, want)
+	}
+	if got, want := len(now.Domains()), 2; got != want {
+		t.Errorf("got %d domains at HEAD, want %d", got, want)
+	}
+	if len(old.Metadata().Commit) != 40 || old.Metadata().Commit == now.Metadata().Commit {
+		t.Errorf("got commits %q and %q, want two distinct hashes", old.Metadata().Commit, now.Metadata().Commit)
+	}
+	if got, want := old.Metadata().Modified.Format("2006-01-02"), "2026-10-01"; got != want {
+		t.Errorf("got commit date %s, want %s", got, want)
+	}
+	if !strings.Contains(old.Summary("Old"), "Commit: "+old.Metadata().Commit) {
+		t.Errorf("got a summary without the commit")
+	}
+}
+
+func TestGitSourceMissing(t *testing.T) {
+	// testing missing revisions and paths
+	repo := gitRepo(t, "0.0.0.0 aa.com\n")
+
+	for _, location := range []string{"git:" + repo + "@nosuchrev:hosts", "git:" + repo + ":nosuchfile"} {
+		hf := New(Options{})
+		if err := hf.Load(context.Background(), location); err == nil {
+			t.Errorf("%s: got no error", location)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package hosts
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// headerDateLayouts are the date formats found in the headers of common hosts lists.
+var headerDateLayouts = []string{
+	"2 January 2006 15:04:05 (MST)",
+	"2 January 2006 15:04:05 MST",
+	"2 January 2006 15:04:05",
+	"2 January 2006",
+	"January 2, 2006 15:04:05 MST",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"02 Jan 2006 15:04:05",
+	"02 Jan 2006",
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC3339,
+	"Mon Jan _2 15:04:05 MST 2006",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"02/01/2006",
+}
+
+// headerDateKey matches the header lines that announce a date.
+var headerDateKey = regexp.MustCompile(`(?i)(date|updated|modified|last change)`)
+
+// HeaderDate returns the date announced in the header of the list, such as
+// "# Date: 19 October 2026 05:11:19 (UTC)", or the zero time if there is none.
+func (h *Hosts) HeaderDate() time.Time {
+	for _, line := range h.header {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#! \t"))
+		i := strings.Index(line, ":")
+		if i < 0 || !headerDateKey.MatchString(line[:i]) {
+			continue
+		}
+		if t, ok := parseHeaderDate(strings.TrimSpace(line[i+1:])); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Updated returns when the list last changed: the date in its header, or
+// else the modification time its Source reported.
+func (h *Hosts) Updated() time.Time {
+	if t := h.HeaderDate(); !t.IsZero() {
+		return t
+	}
+	return h.meta.Modified
+}
+
+func parseHeaderDate(value string) (time.Time, bool) {
+	for _, layout := range headerDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,125 @@
+package hosts
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A HistoryPoint is the state of a hosts file at one commit.
+type HistoryPoint struct {
+	Commit  Commit
+	Domains int // the number of domains
+	TLDs    int // the number of distinct TLDs
+	Added   int // domains added since the previous point
+	Removed int // domains removed since the previous point
+}
+
+// GitLog returns the commits, reachable from rev, that touched a path,
+// oldest first.
+func GitLog(ctx context.Context, repo, rev, path string) ([]Commit, error) {
+	out, err := git(ctx, repo, "log", "--reverse", commitFormat, rev, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(out), nil
+}
+
+// History walks the commits of a git location, git:<repo>@<rev>:<path>,
+// that touched its file, oldest first, and records how the list changed at
+// each.
+func History(ctx context.Context, g GitSource) ([]HistoryPoint, error) {
+	commits, err := GitLog(ctx, g.Repo, g.Rev, g.Path)
+	if err != nil {
+		return nil, &LoadError{g.String(), ErrNotFound, err}
+	}
+
+	var points []HistoryPoint
+	previous := DomainSet{}
+	for _, c := range commits {
+		h, err := loadCommit(ctx, g, c.Hash)
+		if err != nil {
+			return nil, err
+		}
+		current := h.Set()
+		kept := current.IntersectionLen(previous)
+		points = append(points, HistoryPoint{
+			Commit:  c,
+			Domains: len(current),
+			TLDs:    len(h.TLDs()),
+			Added:   len(current) - kept,
+			Removed: len(previous) - kept,
+		})
+		previous = current
+	}
+	return points, nil
+}
+
+// loadCommit loads the file of a git location at a commit. A file deleted,
+// or emptied, at that commit is an empty list.
+func loadCommit(ctx context.Context, g GitSource, hash string) (*Hosts, error) {
+	h := New(Options{})
+	err := h.LoadSource(ctx, GitSource{Repo: g.Repo, Rev: hash, Path: g.Path})
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrEmpty) {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		h.Reset()
+		return h, nil
+	}
+	return h, err
+}
+
+// WriteHistoryCSV writes a history as CSV, with a header row.
+func WriteHistoryCSV(w io.Writer, points []HistoryPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"commit", "date", "domains", "tlds", "added", "removed", "author", "subject"})
+	for _, p := range points {
+		cw.Write([]string{
+			p.Commit.Hash,
+			p.Commit.Date.Format(time.RFC3339),
+			strconv.Itoa(p.Domains),
+			strconv.Itoa(p.TLDs),
+			strconv.Itoa(p.Added),
+			strconv.Itoa(p.Removed),
+			p.Commit.Author,
+			p.Commit.Subject,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// historyJSON is the JSON layout of a HistoryPoint.
+type historyJSON struct {
+	Commit  string    `json:"commit"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+	Domains int       `json:"domains"`
+	TLDs    int       `json:"tlds"`
+	Added   int       `json:"added"`
+	Removed int       `json:"removed"`
+}
+
+// WriteHistoryJSON writes a history as a JSON array.
+func WriteHistoryJSON(w io.Writer, points []HistoryPoint) error {
+	out := []historyJSON{}
+	for _, p := range points {
+		out = append(out, historyJSON{p.Commit.Hash, p.Commit.Date, p.Commit.Author, p.Commit.Subject, p.Domains, p.TLDs, p.Added, p.Removed})
+	}
+	return writeJSON(w, out)
+}
+
+// writeJSON writes v as an indented JSON document, with its HTML characters
+// left as they are.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
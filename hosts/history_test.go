@@ -0,0 +1,56 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	// testing the timeline of a hosts file across commits
+	repo := gitRepo(t,
+		"0.0.0.0 aa.com\n",
+		"0.0.0.0 aa.com\n0.0.0.0 bb.com\n0.0.0.0 cc.net\n",
+		"0.0.0.0 bb.com\n",
+	)
+
+	points, err := History(context.Background(), GitSource{Repo: repo, Rev: "HEAD", Path: "hosts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(points), 3; got != want {
+		t.Fatalf("got %d points, want %d", got, want)
+	}
+
+	want := []HistoryPoint{
+		{Domains: 1, TLDs: 1, Added: 1, Removed: 0},
+		{Domains: 3, TLDs: 2, Added: 2, Removed: 0},
+		{Domains: 1, TLDs: 1, Added: 0, Removed: 2},
+	}
+	for i, p := range points {
+		p.Commit = Commit{}
+		if p != want[i] {
+			t.Errorf("point %d: got %+v, want %+v", i, p, want[i])
+		}
+	}
+	if got, want := points[0].Commit.Subject, "version 1"; got != want {
+		t.Errorf("got first subject %q, want %q", got, want)
+	}
+
+	var csv strings.Builder
+	WriteHistoryCSV(&csv, points)
+	if got, want := strings.Count(csv.String(), "\n"), 4; got != want {
+		t.Errorf("got %d CSV lines, want %d", got, want)
+	}
+
+	var js strings.Builder
+	WriteHistoryJSON(&js, points)
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal([]byte(js.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded[2]["removed"], 2.0; got != want {
+		t.Errorf("got removed %v, want %v", got, want)
+	}
+}
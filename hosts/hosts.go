@@ -0,0 +1,447 @@
+// Package hosts loads, analyzes, compares, and formats hosts files.
+package hosts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Options control how a Hosts list is processed and summarized.
+type Options struct {
+	Sort bool // sort the domains by domain, TLD, subdomain, and so on
+	TLD  bool // include the TLD tally in the summary
+	Root bool // include the root domain tally in the summary
+
+	Query *Query // keep only the domains that match this query
+}
+
+// OutputOptions control how a Hosts list is written out.
+type OutputOptions struct {
+	IP       string // the IP address prefix of each line
+	Plain    bool   // write plain domains, with no IP address prefix
+	NoHeader bool   // omit the header of the source
+	Defaults bool   // include the default hosts at the top
+}
+
+// A Thingtally is the tally of domains under a TLD or a root domain.
+type Thingtally struct {
+	Thing string
+	Tally int
+}
+
+// A Hosts struct holds all the facets of a collection of hosts.
+type Hosts struct {
+	Options
+
+	raw         []byte
+	location    string
+	meta        Metadata
+	header      []string
+	domains     []string
+	tlds        map[string]int
+	tldTallies  []Thingtally
+	roots       map[string]int
+	rootTallies []Thingtally
+	duplicates  []string
+	unfiltered  int // the number of domains before the query
+}
+
+// New returns an empty, unloaded, Hosts list.
+func New(o Options) *Hosts {
+	h := &Hosts{Options: o}
+	h.Reset()
+	return h
+}
+
+// Raw returns the bytes of the source, as loaded.
+func (h *Hosts) Raw() []byte { return h.raw }
+
+// Location returns where the list was loaded from.
+func (h *Hosts) Location() string { return h.location }
+
+// Metadata returns what the Source reported about the list.
+func (h *Hosts) Metadata() Metadata { return h.meta }
+
+// Header returns the leading comment and blank lines of the source.
+func (h *Hosts) Header() []string { return h.header }
+
+// Domains returns the deduplicated domains of the list.
+func (h *Hosts) Domains() []string { return h.domains }
+
+// TLDs returns the tally of domains by TLD.
+func (h *Hosts) TLDs() map[string]int { return h.tlds }
+
+// TLDTallies returns the tally of domains by TLD, largest first.
+func (h *Hosts) TLDTallies() []Thingtally { return h.tldTallies }
+
+// Roots returns the tally of domains by root domain.
+func (h *Hosts) Roots() map[string]int { return h.roots }
+
+// RootTallies returns the tally of domains by root domain, largest first.
+func (h *Hosts) RootTallies() []Thingtally { return h.rootTallies }
+
+// Duplicates returns the domains that appeared more than once in the source.
+func (h *Hosts) Duplicates() []string { return h.duplicates }
+
+// Reset the Hosts structure to an initial, unloaded state.
+func (h *Hosts) Reset() bool {
+	// zero everything
+	h.raw = []byte{}
+	h.location = ""
+	h.meta = Metadata{}
+	h.header = []string{}
+	h.domains = []string{}
+	h.tlds = map[string]int{}
+	h.tldTallies = []Thingtally{}
+	h.roots = map[string]int{}
+	h.rootTallies = []Thingtally{}
+	h.duplicates = []string{}
+	h.unfiltered = 0
+
+	return true
+}
+
+// summarize the hosts
+func (h *Hosts) Summary(prefix string) string {
+	var summary []string
+	sepLen := 40
+
+	summary = append(summary, strings.Repeat("-", sepLen))
+	summary = append(summary, prefix+" summary:")
+	summary = append(summary, strings.Repeat("-", sepLen))
+	summary = append(summary, "Location: "+h.location)
+	if len(h.meta.Commit) > 0 {
+		summary = append(summary, "Commit: "+h.meta.Commit+" ("+h.meta.Modified.Format("2006-01-02 15:04:05 -0700")+")")
+	}
+	if len(h.meta.Failed) > 0 {
+		summary = append(summary, "Fallback: served by "+h.meta.Mirror+", after "+strings.Join(h.meta.Failed, ", ")+" failed")
+	}
+	summary = append(summary, "Domains: "+humanize.Comma(int64(len(h.domains))))
+	if h.Query != nil {
+		summary = append(summary, "Query: "+h.Query.String()+" ("+humanize.Comma(int64(len(h.domains)))+" of "+humanize.Comma(int64(h.unfiltered))+" domains)")
+	}
+	summary = append(summary, "Bytes: "+humanize.Bytes(uint64(int64(len(h.raw)))))
+	if h.TLD {
+		var s []string
+		for _, t := range h.tldTallies {
+			s = append(s, t.Thing+": "+humanize.Comma(int64(t.Tally)))
+		}
+		summary = append(summary, "TLD tally:  ("+humanize.Comma(int64(len(s)))+" unique TLD)\n   "+strings.Join(s, "\n   "))
+		summary = append(summary, strings.Repeat("-", sepLen))
+	}
+	if h.Root {
+		var s []string
+		for _, t := range h.rootTallies {
+			s = append(s, t.Thing+": "+humanize.Comma(int64(t.Tally)))
+		}
+		summary = append(summary, "Root domain tally:  ("+humanize.Comma(int64(len(s)))+" unique root domais)\n   "+strings.Join(s, "\n   "))
+		summary = append(summary, strings.Repeat("-", sepLen))
+	}
+
+	return strings.Join(summary[:], "\n")
+}
+
+// Output writes the list in hosts format, or as plain domains.
+func (h *Hosts) Output(w io.Writer, o OutputOptions) error {
+	var lines []string
+
+	// first, the header
+	if !o.NoHeader {
+		lines = append(lines, h.header...)
+	}
+
+	// add defaults
+	if o.Defaults && !o.Plain {
+		lines = append(lines,
+			"127.0.0.1 localhost",
+			"127.0.0.1 localhost.localdomain",
+			"127.0.0.1 local",
+			"255.255.255.255 broadcasthost",
+			"::1 localhost",
+			"::1 ip6-localhost",
+			"::1 ip6-loopback",
+			"fe80::1%lo0 localhost",
+			"ff00::0 ip6-localnet",
+			"ff00::0 ip6-mcastprefix",
+			"ff02::1 ip6-allnodes",
+			"ff02::2 ip6-allrouters",
+			"ff02::3 ip6-allhosts",
+			"0.0.0.0 0.0.0.0",
+			"",
+		)
+	}
+
+	for i := range h.domains {
+		if o.Plain {
+			lines = append(lines, h.domains[i])
+		} else {
+			lines = append(lines, o.IP+" "+h.domains[i])
+		}
+	}
+
+	for i := range lines {
+		if _, err := fmt.Fprintln(w, lines[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Intersection returns the domains found in both lists, in alphabetical order.
+func (h *Hosts) Intersection(other *Hosts) []string {
+	return h.Set().Intersection(other.Set())
+}
+
+// Unique returns the domains of this list that are not in the other list,
+// in alphabetical order.
+func (h *Hosts) Unique(other *Hosts) []string {
+	return h.Set().Difference(other.Set())
+}
+
+// checkEvery is how many lines process handles between checks for cancellation.
+const checkEvery = 10000
+
+func (h *Hosts) process(ctx context.Context) error {
+	// make a slice with the lines from the Raw domains
+	slc := strings.Split(string(h.raw), "\n")
+
+	// Step: preserve the header
+	for i := range slc {
+		tst := strings.TrimSpace(slc[i])
+		if strings.HasPrefix(tst, "#") || len(tst) == 0 {
+			h.header = append(h.header, slc[i])
+		} else {
+			break
+		}
+	}
+
+	// Step: basic cleanup
+	for i := range slc {
+		// remove embedded comments
+		slc[i] = strings.Split(slc[i], "#")[0]
+
+		// remove all extra whitespace
+		words := strings.Fields(slc[i])
+		slc[i] = strings.Join(words, " ")
+
+		// lowercase everything
+		slc[i] = strings.ToLower(slc[i])
+	}
+
+	// Step: discard blank lines
+	slc = h.filter(slc, h.notEmpty)
+
+	// step: line match regex for ip address, domain, or host
+	// This regex matches domain, or host
+	r, _ := regexp.Compile("((^(?:[a-z_0-9](?:[a-z_0-9-]{0,61}[a-z_0-9])?\\.)+[a-z_0-9][a-z_0-9-]{0,61}[a-z_0-9]$)|((^(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?))(\\s+((?:[a-z_0-9](?:[a-z_0-9-]{0,61}[a-z_0-9])?\\.)+[a-z_0-9][a-z_0-9-]{0,61}[a-z_0-9]\\s*)+$)))")
+	var matchSlice []string
+	for i := range slc {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if r.MatchString(slc[i]) {
+			words := strings.Fields(slc[i])
+			if net.ParseIP(words[0]) == nil {
+				// no IP segment - handle case of multi-host line
+				newSlice := strings.Split(slc[i], " ")
+				matchSlice = append(matchSlice, newSlice...)
+			} else {
+				// remove the IP segment
+				newSlice := strings.Split(strings.Join(words[1:], " "), " ")
+				matchSlice = append(matchSlice, newSlice...)
+			}
+		}
+	}
+	slc = matchSlice
+
+	// we could bail at this juncture
+	if len(slc) == 0 {
+		return nil
+	}
+
+	// regular string sort for deduplication
+	sort.Sort(sort.StringSlice(slc))
+
+	// deduplicate
+	j := 0
+	for i := 1; i < len(slc); i++ {
+		if slc[j] == slc[i] {
+			h.duplicates = append(h.duplicates, slc[j])
+			continue
+		}
+		j++
+		slc[j] = slc[i]
+	}
+	slc = slc[:j+1]
+	h.unfiltered = len(slc)
+
+	// keep the domains that match the query
+	if h.Query != nil {
+		matched := slc[:0]
+		for _, d := range slc {
+			if h.Query.Match(d) {
+				matched = append(matched, d)
+			}
+		}
+		slc = matched
+	}
+
+	// tally TLDs
+	h.tlds, h.tldTallies = tally(slc, TLD)
+
+	// tally Roots
+	h.roots, h.rootTallies = tally(slc, Root)
+
+	// custom domain sorting
+	if h.Sort {
+		sort.Sort(domainSort(slc))
+	}
+
+	// Stash our slice of domains.
+	h.domains = slc
+
+	return ctx.Err()
+}
+
+// TLD returns the top-level domain of a domain, or "" if it has none.
+func TLD(domain string) string {
+	ss := strings.Split(domain, ".")
+	if len(ss) > 1 {
+		return ss[len(ss)-1]
+	}
+	return ""
+}
+
+// Root returns the root domain, the last two labels, of a domain, or "" if it has none.
+func Root(domain string) string {
+	ss := strings.Split(domain, ".")
+	if len(ss) > 1 {
+		return ss[len(ss)-2] + "." + ss[len(ss)-1]
+	}
+	return ""
+}
+
+// tally counts the domains by the key that thing computes, and orders the
+// tallies largest first.
+func tally(slc []string, thing func(string) string) (map[string]int, []Thingtally) {
+	things := make(map[string]int)
+	tallies := []Thingtally{}
+	m := map[string]int{}
+	n := map[int][]string{}
+	for i := range slc {
+		s := thing(slc[i])
+		if len(s) > 0 {
+			m[s] = m[s] + 1
+		}
+	}
+	var a []int
+	for k, v := range m {
+		n[v] = append(n[v], k)
+	}
+	for k := range n {
+		a = append(a, k)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(a)))
+
+	for _, k := range a {
+		for _, s := range n[k] {
+			things[s] = k
+			tallies = append(tallies, Thingtally{s, k})
+		}
+	}
+	return things, tallies
+}
+
+func (h Hosts) length() int {
+	return len(h.domains)
+}
+
+func (h Hosts) filter(vs []string, f func(string) bool) []string {
+	vsf := make([]string, 0)
+	for _, v := range vs {
+		if f(v) {
+			vsf = append(vsf, v)
+		}
+	}
+	return vsf
+}
+
+func (h Hosts) notEmpty(s string) bool {
+	return len(s) > 0
+}
+
+func (h Hosts) notComment(s string) bool {
+	return !strings.HasPrefix(s, "#")
+}
+
+func (h Hosts) scrub(s string, r string) string {
+	return strings.ReplaceAll(s, r, "")
+}
+
+func (h Hosts) replace(s string, r string, n string) string {
+	return strings.ReplaceAll(s, r, n)
+}
+
+// Structure and functions for custom domain sorting.
+type domainSort []string
+
+func (s domainSort) Len() int {
+	return len(s)
+}
+func (s domainSort) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s domainSort) Less(i, j int) bool {
+	return Normalize(s[i]) < Normalize(s[j])
+}
+
+// Normalize the host string for sorting
+func Normalize(c string) string {
+	pad := " "
+	length := 50
+	cslice := strings.Split(c, ".")
+	parts := len(cslice)
+	out := c
+	if parts > 1 {
+		out = padRight(cslice[parts-2], length, pad)
+		out += padRight(cslice[parts-1], length, pad)
+		reverseSlice := reverse(cslice)
+		if parts > 2 {
+			slc := reverseSlice[2:]
+			for i := range slc {
+				out += padRight(slc[i], length, " ")
+			}
+		}
+	}
+	return out
+}
+
+func times(str string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(str, n)
+}
+
+func padRight(str string, length int, pad string) string {
+	return str + times(pad, length-len(str))
+}
+
+func reverse(a []string) []string {
+	for i := len(a)/2 - 1; i >= 0; i-- {
+		opp := len(a) - 1 - i
+		a[i], a[opp] = a[opp], a[i]
+	}
+	return a
+}
@@ -1,16 +1,18 @@
-package main
+package hosts
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 )
 
 func TestMultihostLines(t *testing.T) {
 	// testing for splitting multiple Domains per line into individual lines
-	hf := Hosts{}
-	hf.Load("./test/hosts-multi")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-multi")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	wantmorethan := 1
 
 	if got <= wantmorethan {
@@ -20,10 +22,10 @@ func TestMultihostLines(t *testing.T) {
 
 func TestTLD(t *testing.T) {
 	// testing for TLD Tallies
-	hf := Hosts{}
-	hf.Load("./test/hosts-multi")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-multi")
 
-	got := len(hf.TLDs)
+	got := len(hf.TLDs())
 	wantmorethan := 1
 
 	if got <= wantmorethan {
@@ -33,10 +35,10 @@ func TestTLD(t *testing.T) {
 
 func TestPreserveHeadder(t *testing.T) {
 	// testing file header preservation.
-	hf := Hosts{}
-	hf.Load("./test/hosts-comments-embedded")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-comments-embedded")
 
-	got := len(hf.Header)
+	got := len(hf.Header())
 	want := 4
 
 	if got != want {
@@ -46,10 +48,10 @@ func TestPreserveHeadder(t *testing.T) {
 
 func TestEmbeddedComments(t *testing.T) {
 	// testing hosts lines with embedded comments
-	hf := Hosts{}
-	hf.Load("./test/hosts-comments-embedded")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-comments-embedded")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 4
 
 	if got != want {
@@ -59,12 +61,12 @@ func TestEmbeddedComments(t *testing.T) {
 
 func TestDuplicates(t *testing.T) {
 	// testing hosts with duplicates
-	hf := Hosts{}
-	hf.Load("./test/hosts-duplicates")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-duplicates")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 5
-	dupesgot := len(hf.Duplicates)
+	dupesgot := len(hf.Duplicates())
 	dupeswant := 3
 
 	if got != want {
@@ -78,10 +80,10 @@ func TestDuplicates(t *testing.T) {
 
 func TestJustText(t *testing.T) {
 	// testing a file with just text, no hosts
-	hf := Hosts{}
-	hf.Load("./test/hosts-text")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-text")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 0
 
 	if got != want {
@@ -91,10 +93,10 @@ func TestJustText(t *testing.T) {
 
 func TestUrl(t *testing.T) {
 	// testing for splitting multiple Domains per line into individual lines
-	hf := Hosts{}
-	hf.Load("https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts")
+	hf := New(Options{})
+	hf.Load(context.Background(), "https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	wantmorethan := 1
 
 	if got <= wantmorethan {
@@ -104,10 +106,10 @@ func TestUrl(t *testing.T) {
 
 func TestUrlJustText(t *testing.T) {
 	// testing a file with just text, no hosts
-	hf := Hosts{}
-	hf.Load("https://news.ycombinator.com/")
+	hf := New(Options{})
+	hf.Load(context.Background(), "https://news.ycombinator.com/")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 0
 
 	if got != want {
@@ -117,11 +119,10 @@ func TestUrlJustText(t *testing.T) {
 
 func TestSorting(t *testing.T) {
 	// testing hosts with duplicates
-	hf := Hosts{}
 	a := "aa.ca"
 	b := "zz.aa"
 
-	got := hf.Normalize(a) < hf.Normalize(b)
+	got := Normalize(a) < Normalize(b)
 	want := true
 
 	if got != want {
@@ -131,7 +132,7 @@ func TestSorting(t *testing.T) {
 	a = "cc.ca"
 	b = "aa.cc.ca"
 
-	got = hf.Normalize(a) < hf.Normalize(b)
+	got = Normalize(a) < Normalize(b)
 	want = true
 
 	if got != want {
@@ -141,10 +142,10 @@ func TestSorting(t *testing.T) {
 
 func TestMultiPlaintext(t *testing.T) {
 	// testing for splitting multiple Domains per line into individual lines
-	hf := Hosts{}
-	hf.Load("./test/hosts-plain-list")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-plain-list")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 3
 
 	if got != want {
@@ -154,28 +155,49 @@ func TestMultiPlaintext(t *testing.T) {
 
 func TestMash(t *testing.T) {
 	// testing for splitting multiple Domains per line into individual lines
-	hf := Hosts{}
-	hf.Load("./test/hosts-mash")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-mash")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 8
 
 	if got != want {
 		t.Errorf("got %d domain, want %d", got, want)
-		fmt.Println(hf.Domains)
+		fmt.Println(hf.Domains())
 	}
 }
 
 func TestUnderscores(t *testing.T) {
 	// testing for splitting multiple Domains per line into individual lines
-	hf := Hosts{}
-	hf.Load("./test/hosts-underscores")
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-underscores")
 
-	got := len(hf.Domains)
+	got := len(hf.Domains())
 	want := 2
 
 	if got != want {
 		t.Errorf("got %d domain, want %d", got, want)
-		fmt.Println(hf.Domains)
+		fmt.Println(hf.Domains())
+	}
+}
+
+func TestOutput(t *testing.T) {
+	// testing hosts and plain output of a list
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-plain-list")
+
+	var b strings.Builder
+	hf.Output(&b, OutputOptions{IP: "0.0.0.0", NoHeader: true})
+	got := strings.Count(b.String(), "0.0.0.0 ")
+	want := 3
+
+	if got != want {
+		t.Errorf("got %d hosts lines, want %d", got, want)
+	}
+
+	b.Reset()
+	hf.Output(&b, OutputOptions{Plain: true, NoHeader: true})
+	if strings.Contains(b.String(), "0.0.0.0") {
+		t.Errorf("got an IP prefix in plain output")
 	}
 }
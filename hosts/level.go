@@ -0,0 +1,145 @@
+package hosts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/net/publicsuffix"
+)
+
+// A Level is the granularity at which lists are compared.
+type Level string
+
+// The levels of comparison.
+const (
+	ExactLevel Level = "exact" // whole hostnames
+	RootLevel  Level = "root"  // registrable domains, one label below a public suffix
+	TLDLevel   Level = "tld"   // TLDs, as tallied by TLD
+)
+
+// ParseLevel parses a level of comparison: exact, root, or tld.
+func ParseLevel(s string) (Level, error) {
+	switch l := Level(s); l {
+	case ExactLevel, RootLevel, TLDLevel:
+		return l, nil
+	}
+	return "", fmt.Errorf("%s: want exact, root, or tld", s)
+}
+
+// A Shared is a hostname, root domain, or TLD found in two lists, with the
+// number of hostnames each has under it.
+type Shared struct {
+	Key      string `json:"key"`
+	Main     int    `json:"main"`
+	Compared int    `json:"compared"`
+}
+
+// A LevelComparison is how two lists overlap once their hostnames are
+// collapsed to a level.
+type LevelComparison struct {
+	Level          Level
+	From, To       string   // the locations of the main and compared lists
+	Main, Compared int      // the number of distinct keys in each list
+	Shared         []Shared // the keys in both lists, with the most hostnames first
+}
+
+// CompareAt compares the list to another once both are collapsed to a level,
+// building on the TLD tallies of each.
+func (h *Hosts) CompareAt(other *Hosts, level Level) LevelComparison {
+	a, b := h.keys(level), other.keys(level)
+	c := LevelComparison{Level: level, From: h.location, To: other.location, Main: len(a), Compared: len(b), Shared: []Shared{}}
+	for key, n := range a {
+		if m, ok := b[key]; ok {
+			c.Shared = append(c.Shared, Shared{key, n, m})
+		}
+	}
+	sort.Slice(c.Shared, func(i, j int) bool {
+		s, t := c.Shared[i], c.Shared[j]
+		if s.Main+s.Compared != t.Main+t.Compared {
+			return s.Main+s.Compared > t.Main+t.Compared
+		}
+		return s.Key < t.Key
+	})
+	return c
+}
+
+// keys returns the hostnames under each key of a level. Hostnames with no
+// registrable domain, or no TLD, are left out.
+func (h *Hosts) keys(level Level) map[string]int {
+	var tallies map[string]int
+	switch level {
+	case RootLevel:
+		// Unlike Root, this keeps a.foo.co.uk and b.bar.co.uk apart.
+		tallies = map[string]int{}
+		for _, d := range h.domains {
+			if root, err := publicsuffix.EffectiveTLDPlusOne(d); err == nil {
+				tallies[root]++
+			}
+		}
+	case TLDLevel:
+		tallies = h.tlds
+	default:
+		tallies = map[string]int{}
+		for _, d := range h.domains {
+			tallies[d] = 1
+		}
+	}
+	keys := map[string]int{}
+	for k, n := range tallies {
+		if len(k) > 0 {
+			keys[k] = n
+		}
+	}
+	return keys
+}
+
+// Write writes the counts of keys, then the top shared keys, or all of them
+// when top is 0, as an aligned table.
+func (c LevelComparison) Write(w io.Writer, top int) error {
+	name := map[Level]string{ExactLevel: "hostnames", RootLevel: "root domains", TLDLevel: "TLDs"}[c.Level]
+	fmt.Fprintf(w, "Shared %s: %s, of %s in the main list and %s in the compared list\n",
+		name, humanize.Comma(int64(len(c.Shared))), humanize.Comma(int64(c.Main)), humanize.Comma(int64(c.Compared)))
+	if len(c.Shared) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nSHARED\tMAIN\tCOMPARED")
+	for i, s := range c.Shared {
+		if top > 0 && i == top {
+			fmt.Fprintf(tw, "...\t%s more\n", humanize.Comma(int64(len(c.Shared)-top)))
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, humanize.Comma(int64(s.Main)), humanize.Comma(int64(s.Compared)))
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes every shared key as CSV, with a header row.
+func (c LevelComparison) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{string(c.Level), "main", "compared"})
+	for _, s := range c.Shared {
+		cw.Write([]string{s.Key, strconv.Itoa(s.Main), strconv.Itoa(s.Compared)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the comparison as a JSON document, with every shared key.
+func (c LevelComparison) WriteJSON(w io.Writer) error {
+	out := struct {
+		Level    Level    `json:"level"`
+		From     string   `json:"from"`
+		To       string   `json:"to"`
+		Main     int      `json:"main"`
+		Compared int      `json:"compared"`
+		Count    int      `json:"shared_count"`
+		Shared   []Shared `json:"shared"`
+	}{c.Level, c.From, c.To, c.Main, c.Compared, len(c.Shared), c.Shared}
+	return writeJSON(w, out)
+}
@@ -0,0 +1,75 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompareAt(t *testing.T) {
+	// testing comparisons of lists collapsed to root domains and TLDs
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 a.foo.com b.foo.com c.foo.com bar.net x.baz.org\n"))
+	compare := New(Options{})
+	compare.LoadBytes(context.Background(), "compare", []byte("0.0.0.0 z.foo.com y.bar.net w.bar.net qux.org\n"))
+
+	if got, want := len(main.Intersection(compare)), 0; got != want {
+		t.Errorf("got %d shared hostnames, want %d", got, want)
+	}
+
+	c := main.CompareAt(compare, RootLevel)
+	if got, want := c.Shared, []Shared{{"foo.com", 3, 1}, {"bar.net", 1, 2}}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got shared roots %v, want %v", got, want)
+	}
+	if got, want := c.Main, 3; got != want {
+		t.Errorf("got %d roots in the main list, want %d", got, want)
+	}
+
+	uk := New(Options{})
+	uk.LoadBytes(context.Background(), "uk", []byte("0.0.0.0 a.foo.co.uk\n"))
+	other := New(Options{})
+	other.LoadBytes(context.Background(), "other", []byte("0.0.0.0 b.bar.co.uk c.foo.co.uk\n"))
+	if got, want := uk.CompareAt(other, RootLevel).Shared, []Shared{{"foo.co.uk", 1, 1}}; len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("got shared roots %v, want %v", got, want)
+	}
+
+	c = main.CompareAt(compare, TLDLevel)
+	if got, want := len(c.Shared), 3; got != want {
+		t.Errorf("got %d shared TLDs, want %d", got, want)
+	}
+
+	c = main.CompareAt(compare, ExactLevel)
+	if got, want := len(c.Shared), 0; got != want {
+		t.Errorf("got %d shared hostnames, want %d", got, want)
+	}
+
+	var out strings.Builder
+	main.CompareAt(compare, RootLevel).Write(&out, 1)
+	if !strings.HasPrefix(out.String(), "Shared root domains: 2, of 3 in the main list and 3 in the compared list\n") {
+		t.Errorf("got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "1 more") {
+		t.Errorf("got %q, want the shared roots beyond the top 1 left out", out.String())
+	}
+
+	out.Reset()
+	main.CompareAt(compare, RootLevel).WriteCSV(&out)
+	if got, want := out.String(), "root,main,compared\nfoo.com,3,1\nbar.net,1,2\n"; got != want {
+		t.Errorf("got CSV %q, want %q", got, want)
+	}
+
+	out.Reset()
+	main.CompareAt(compare, RootLevel).WriteJSON(&out)
+	var decoded struct{ Shared []Shared }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Shared[1].Compared, 2; got != want {
+		t.Errorf("got %d in JSON, want %d", got, want)
+	}
+
+	if _, err := ParseLevel("etld"); err == nil {
+		t.Errorf("got no error for an unknown level, want one")
+	}
+}
@@ -0,0 +1,65 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+)
+
+// Load (generically) a list of hosts from any location a registered Source
+// understands, such as a URL, "clip:", "-" for stdin, or a local file.
+// It returns a *LoadError on failure.
+func (h *Hosts) Load(ctx context.Context, location string) error {
+	// a wrapper to provide a clean loading interface
+	src, err := NewSource(location)
+	if err != nil {
+		return &LoadError{location, nil, err}
+	}
+	return h.LoadSource(ctx, src)
+}
+
+// LoadSource loads a list of hosts from a Source.
+func (h *Hosts) LoadSource(ctx context.Context, src Source) error {
+	h.Reset()
+	r, meta, err := src.Open(ctx)
+	h.meta = meta
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		var le *LoadError
+		if errors.As(err, &le) {
+			return err
+		}
+		return &LoadError{meta.Location, nil, err}
+	}
+	return h.loadRaw(ctx, meta.Location, raw)
+}
+
+// LoadBytes loads a list of hosts already in memory. The location names
+// where the bytes came from.
+func (h *Hosts) LoadBytes(ctx context.Context, location string, raw []byte) error {
+	h.Reset()
+	h.meta = Metadata{Location: location}
+	return h.loadRaw(ctx, location, raw)
+}
+
+// loadRaw processes the raw bytes of a hosts list, and checks that it
+// yielded some domains.
+func (h *Hosts) loadRaw(ctx context.Context, location string, raw []byte) error {
+	if isBinary(raw) {
+		return &LoadError{location, ErrDecode, nil}
+	}
+	h.location = location
+	h.raw = raw
+	if err := h.process(ctx); err != nil {
+		return &LoadError{location, nil, err}
+	}
+	if h.unfiltered == 0 {
+		return &LoadError{location, ErrEmpty, nil}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCanceledProcessing(t *testing.T) {
+	// testing that processing stops when its context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hf := New(Options{})
+	err := hf.LoadBytes(ctx, "canceled", []byte("0.0.0.0 aa.com\n"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	// testing that a deadline cancels an in-flight request
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	hf := New(Options{})
+	start := time.Now()
+	err := hf.Load(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got %v to cancel, want well under a second", elapsed)
+	}
+}
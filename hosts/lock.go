@@ -0,0 +1,129 @@
+package hosts
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Pin records the size and SHA-256 checksum of a resolved hosts source.
+type Pin struct {
+	Location string
+	Size     int
+	Sum      string
+}
+
+// A Lockfile holds the pins of every source we have resolved, keyed by location.
+type Lockfile struct {
+	Path string
+	Pins map[string]Pin
+}
+
+// NewPin computes the pin for a loaded hosts list.
+func NewPin(h *Hosts) Pin {
+	sum := sha256.Sum256(h.raw)
+	return Pin{
+		Location: h.location,
+		Size:     len(h.raw),
+		Sum:      "sha256:" + hex.EncodeToString(sum[:]),
+	}
+}
+
+func (p Pin) String() string {
+	return p.Location + " " + strconv.Itoa(p.Size) + " " + p.Sum
+}
+
+// ReadLockfile reads a lockfile. A missing lockfile yields an empty set of pins.
+func ReadLockfile(path string) (*Lockfile, error) {
+	l := &Lockfile{Path: path, Pins: map[string]Pin{}}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || len(line) == 0 {
+			continue
+		}
+		// The size and sum are the last two words, so a location may
+		// itself contain spaces.
+		rest, sum := lastWord(line)
+		location, sizeWord := lastWord(rest)
+		if len(location) == 0 || !strings.HasPrefix(sum, "sha256:") {
+			return nil, fmt.Errorf("%s:%d: malformed pin", path, n)
+		}
+		size, err := strconv.Atoi(sizeWord)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: malformed size %q", path, n, sizeWord)
+		}
+		l.Pins[location] = Pin{Location: location, Size: size, Sum: sum}
+	}
+	return l, scanner.Err()
+}
+
+// lastWord splits the last space separated word from a line.
+func lastWord(line string) (rest, word string) {
+	line = strings.TrimSpace(line)
+	i := strings.LastIndexAny(line, " \t")
+	return strings.TrimSpace(line[:i+1]), line[i+1:]
+}
+
+// Verify checks a loaded hosts list against its pin.
+func (l *Lockfile) Verify(h *Hosts) error {
+	want, ok := l.Pins[h.location]
+	if !ok {
+		return &LoadError{h.location, ErrMismatch, fmt.Errorf("not pinned in %s", l.Path)}
+	}
+	got := NewPin(h)
+	if got.Size != want.Size {
+		return &LoadError{h.location, ErrMismatch, fmt.Errorf("size mismatch: got %d bytes, pinned %d", got.Size, want.Size)}
+	}
+	if got.Sum != want.Sum {
+		return &LoadError{h.location, ErrMismatch, fmt.Errorf("checksum mismatch: got %s, pinned %s", got.Sum, want.Sum)}
+	}
+	return nil
+}
+
+// Update refreshes the pin for a loaded hosts list, and describes what changed.
+func (l *Lockfile) Update(h *Hosts) string {
+	got := NewPin(h)
+	was, ok := l.Pins[h.location]
+	l.Pins[h.location] = got
+	switch {
+	case !ok:
+		return "+ " + got.String()
+	case was.Sum != got.Sum:
+		return "~ " + got.Location + " " + strconv.Itoa(was.Size) + " -> " + strconv.Itoa(got.Size) + " bytes, " + was.Sum + " -> " + got.Sum
+	default:
+		return "= " + got.Location
+	}
+}
+
+// Write saves the lockfile, with pins sorted by location.
+func (l *Lockfile) Write() error {
+	var locations []string
+	for k := range l.Pins {
+		locations = append(locations, k)
+	}
+	sort.Strings(locations)
+
+	lines := []string{"# ghosts.lock: pinned hosts sources. Regenerate with ghosts -updatelock."}
+	for _, k := range locations {
+		lines = append(lines, l.Pins[k].String())
+	}
+	return ioutil.WriteFile(l.Path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,129 @@
+package hosts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockfileRoundTrip(t *testing.T) {
+	// testing that pins survive a write and a read
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-multi")
+
+	path := filepath.Join(t.TempDir(), "ghosts.lock")
+	lock, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lock.Update(hf); got[0] != '+' {
+		t.Errorf("got %q, want a new pin", got)
+	}
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	reread, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reread.Verify(hf); err != nil {
+		t.Errorf("got %v, want a verified pin", err)
+	}
+}
+
+func TestLockfileSpaces(t *testing.T) {
+	// testing that a pinned location containing a space survives a write and a read
+	dir := t.TempDir()
+	location := filepath.Join(dir, "my hosts.txt")
+	os.WriteFile(location, []byte("0.0.0.0 aa.com\n"), 0644)
+
+	hf := New(Options{})
+	if err := hf.Load(context.Background(), location); err != nil {
+		t.Fatal(err)
+	}
+	lock := &Lockfile{Path: filepath.Join(dir, "ghosts.lock"), Pins: map[string]Pin{}}
+	lock.Update(hf)
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	reread, err := ReadLockfile(lock.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reread.Pins[location], lock.Pins[location]; got != want {
+		t.Errorf("got pin %v, want %v", got, want)
+	}
+	if err := reread.Verify(hf); err != nil {
+		t.Errorf("got %v, want a verified pin", err)
+	}
+}
+
+func TestLockfileMirror(t *testing.T) {
+	// testing that a list served by a mirror verifies against the pin of its primary URL
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/primary" && down {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("0.0.0.0 aa.com\n"))
+	}))
+	defer server.Close()
+	src := MirrorSource{server.URL + "/primary", server.URL + "/mirror"}
+
+	hf := New(Options{})
+	if err := hf.LoadSource(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+	lock := &Lockfile{Path: "ghosts.lock", Pins: map[string]Pin{}}
+	lock.Update(hf)
+
+	down = true
+	mirrored := New(Options{})
+	if err := mirrored.LoadSource(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Verify(mirrored); err != nil {
+		t.Errorf("got %v, want a verified pin", err)
+	}
+	if got := lock.Update(mirrored); got[0] != '=' {
+		t.Errorf("got %q, want the existing pin", got)
+	}
+	if got, want := len(lock.Pins), 1; got != want {
+		t.Errorf("got %d pins, want %d", got, want)
+	}
+}
+
+func TestLockfileMismatch(t *testing.T) {
+	// testing that a tampered source fails verification
+	hf := New(Options{})
+	hf.Load(context.Background(), "../test/hosts-multi")
+
+	lock := &Lockfile{Path: "ghosts.lock", Pins: map[string]Pin{}}
+	lock.Update(hf)
+
+	hf.raw = append(hf.raw, []byte("\n0.0.0.0 tampered.com")...)
+	if err := lock.Verify(hf); err == nil {
+		t.Errorf("got no error, want a size mismatch")
+	}
+
+	other := &Hosts{location: "../test/hosts-main"}
+	if err := lock.Verify(other); err == nil {
+		t.Errorf("got no error, want an unpinned source")
+	}
+}
+
+func TestLockfileMalformed(t *testing.T) {
+	// testing that a malformed lockfile is rejected
+	path := filepath.Join(t.TempDir(), "ghosts.lock")
+	os.WriteFile(path, []byte("https://example.com/hosts 12\n"), 0644)
+
+	if _, err := ReadLockfile(path); err == nil {
+		t.Errorf("got no error, want a malformed pin")
+	}
+}
@@ -0,0 +1,125 @@
+package hosts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+)
+
+// An Overlap is how much each pair of lists have in common.
+type Overlap struct {
+	Names  []string // the names of the lists
+	Sizes  []int    // the number of domains in each list
+	Shared [][]int  // Shared[i][j] is the number of domains in both list i and list j
+}
+
+// NewOverlap computes the pairwise intersections of lists, named by names.
+func NewOverlap(names []string, lists []*Hosts) Overlap {
+	o := Overlap{Names: names, Sizes: make([]int, len(lists)), Shared: make([][]int, len(lists))}
+	sets := make([]DomainSet, len(lists))
+	for i, h := range lists {
+		sets[i] = h.Set()
+		o.Sizes[i] = len(sets[i])
+		o.Shared[i] = make([]int, len(lists))
+	}
+	for i := range lists {
+		o.Shared[i][i] = o.Sizes[i]
+		for j := i + 1; j < len(lists); j++ {
+			n := sets[i].IntersectionLen(sets[j])
+			o.Shared[i][j], o.Shared[j][i] = n, n
+		}
+	}
+	return o
+}
+
+// Jaccard is the Jaccard similarity of lists i and j: the size of their
+// intersection over the size of their union.
+func (o Overlap) Jaccard(i, j int) float64 {
+	union := o.Sizes[i] + o.Sizes[j] - o.Shared[i][j]
+	if union == 0 {
+		return 0
+	}
+	return float64(o.Shared[i][j]) / float64(union)
+}
+
+// Containment is the percentage of the domains of list i that are in list j.
+func (o Overlap) Containment(i, j int) float64 {
+	if o.Sizes[i] == 0 {
+		return 0
+	}
+	return 100 * float64(o.Shared[i][j]) / float64(o.Sizes[i])
+}
+
+// pairs calls f for each pair of lists, in order.
+func (o Overlap) pairs(f func(i, j int)) {
+	for i := range o.Names {
+		for j := i + 1; j < len(o.Names); j++ {
+			f(i, j)
+		}
+	}
+}
+
+// Write writes the overlap as an aligned table, one row per pair of lists.
+// A IN B is the percentage of the domains of A that are in B.
+func (o Overlap) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "A\tB\tDOMAINS A\tDOMAINS B\tSHARED\tJACCARD\tA IN B\tB IN A")
+	o.pairs(func(i, j int) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.3f\t%.1f%%\t%.1f%%\n",
+			o.Names[i], o.Names[j],
+			humanize.Comma(int64(o.Sizes[i])), humanize.Comma(int64(o.Sizes[j])), humanize.Comma(int64(o.Shared[i][j])),
+			o.Jaccard(i, j), o.Containment(i, j), o.Containment(j, i))
+	})
+	return tw.Flush()
+}
+
+// WriteCSV writes the overlap as CSV, with a header row and one row per pair
+// of lists.
+func (o Overlap) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"a", "b", "domains_a", "domains_b", "shared", "jaccard", "a_in_b", "b_in_a"})
+	o.pairs(func(i, j int) {
+		cw.Write([]string{
+			o.Names[i],
+			o.Names[j],
+			strconv.Itoa(o.Sizes[i]),
+			strconv.Itoa(o.Sizes[j]),
+			strconv.Itoa(o.Shared[i][j]),
+			strconv.FormatFloat(o.Jaccard(i, j), 'f', 4, 64),
+			strconv.FormatFloat(o.Containment(i, j), 'f', 2, 64),
+			strconv.FormatFloat(o.Containment(j, i), 'f', 2, 64),
+		})
+	})
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the overlap as a JSON document: the lists with their
+// sizes, and the matrices of shared domains, Jaccard similarity, and
+// containment percentage, indexed like the lists.
+func (o Overlap) WriteJSON(w io.Writer) error {
+	type list struct {
+		Name    string `json:"name"`
+		Domains int    `json:"domains"`
+	}
+	out := struct {
+		Lists       []list      `json:"lists"`
+		Shared      [][]int     `json:"shared"`
+		Jaccard     [][]float64 `json:"jaccard"`
+		Containment [][]float64 `json:"containment"`
+	}{Lists: []list{}, Shared: o.Shared}
+	for i, name := range o.Names {
+		out.Lists = append(out.Lists, list{name, o.Sizes[i]})
+		jaccard, containment := make([]float64, len(o.Names)), make([]float64, len(o.Names))
+		for j := range o.Names {
+			jaccard[j], containment[j] = o.Jaccard(i, j), o.Containment(i, j)
+		}
+		out.Jaccard = append(out.Jaccard, jaccard)
+		out.Containment = append(out.Containment, containment)
+	}
+	return writeJSON(w, out)
+}
@@ -0,0 +1,57 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOverlap(t *testing.T) {
+	// testing the pairwise intersections of several lists
+	var lists []*Hosts
+	for _, raw := range []string{"aa.com bb.com cc.com dd.com", "cc.com dd.com", "dd.com ee.com"} {
+		h := New(Options{})
+		h.LoadBytes(context.Background(), "test", []byte("0.0.0.0 "+raw+"\n"))
+		lists = append(lists, h)
+	}
+
+	o := NewOverlap([]string{"a", "b", "c"}, lists)
+	if got, want := o.Shared[0][1], 2; got != want {
+		t.Errorf("got %d shared by a and b, want %d", got, want)
+	}
+	if got, want := o.Shared[2][1], 1; got != want {
+		t.Errorf("got %d shared by c and b, want %d", got, want)
+	}
+	if got, want := o.Jaccard(0, 2), 0.2; got != want {
+		t.Errorf("got Jaccard %v, want %v", got, want)
+	}
+	if got, want := o.Containment(1, 0), 100.0; got != want {
+		t.Errorf("got containment %v, want %v", got, want)
+	}
+	if got, want := o.Containment(0, 1), 50.0; got != want {
+		t.Errorf("got containment %v, want %v", got, want)
+	}
+
+	var out strings.Builder
+	o.Write(&out)
+	if got, want := strings.Count(out.String(), "\n"), 4; got != want {
+		t.Errorf("got %d table lines, want %d", got, want)
+	}
+
+	out.Reset()
+	o.WriteCSV(&out)
+	if got, want := strings.Split(out.String(), "\n")[1], "a,b,4,2,2,0.5000,50.00,100.00"; got != want {
+		t.Errorf("got CSV row %q, want %q", got, want)
+	}
+
+	out.Reset()
+	o.WriteJSON(&out)
+	var decoded struct{ Shared [][]int }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Shared[1][0], 2; got != want {
+		t.Errorf("got %d shared in JSON, want %d", got, want)
+	}
+}
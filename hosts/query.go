@@ -0,0 +1,167 @@
+package hosts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Query selects domains. It is a list of terms, separated by spaces, that
+// a domain must all satisfy:
+//
+//	tld:xyz           the TLD, as tallied by TLD, is xyz
+//	root:example.com  the root domain, as tallied by Root, is example.com
+//	label:track       one of the labels of the domain is track
+//	domain:ads.a.com  the domain is ads.a.com
+//	depth>=4          the domain has 4 labels or more
+//	len>40            the domain is longer than 40 characters
+//
+// tld, root, label, and domain also take a regular expression, as in
+// label:~track, and != to negate the match. depth and len compare with =, !=,
+// <, <=, >, or >=. A ! before a term negates it.
+type Query struct {
+	text  string
+	terms []func(domain string) bool
+}
+
+// ParseQuery parses a query.
+func ParseQuery(q string) (*Query, error) {
+	query := &Query{text: strings.Join(strings.Fields(q), " ")}
+	for _, t := range strings.Fields(q) {
+		term, ok, err := parseTerm(t)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("query: %s: want a term like tld:xyz, root:example.com, label:~track, depth>=4, or len>40", t)
+		}
+		query.terms = append(query.terms, term)
+	}
+	if len(query.terms) == 0 {
+		return nil, fmt.Errorf("query: empty")
+	}
+	return query, nil
+}
+
+func (q *Query) String() string { return q.text }
+
+// Match reports whether a domain satisfies every term of the query.
+func (q *Query) Match(domain string) bool {
+	for _, term := range q.terms {
+		if !term(domain) {
+			return false
+		}
+	}
+	return true
+}
+
+// termPattern splits a term into its negation, key, operator, and value.
+var termPattern = regexp.MustCompile(`^(!?)([a-z]+)(:~|:|~|!=|<=|>=|=|<|>)(.*)$`)
+
+// parseTerm parses one term of a query. It reports false for a string that
+// is not a term, because its key is unknown.
+func parseTerm(s string) (func(string) bool, bool, error) {
+	m := termPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false, nil
+	}
+	negate, key, op, value := m[1] == "!", m[2], m[3], m[4]
+
+	var term func(string) bool
+	var err error
+	switch key {
+	case "tld", "root", "label", "domain":
+		term, err = stringTerm(key, op, value)
+	case "depth", "len":
+		term, err = numberTerm(key, op, value)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("query: %s: %v", s, err)
+	}
+	if negate {
+		return func(d string) bool { return !term(d) }, true, nil
+	}
+	return term, true, nil
+}
+
+// stringTerm parses a term on the TLD, root, labels, or whole of a domain.
+func stringTerm(key, op, value string) (func(string) bool, error) {
+	var match func(string) bool
+	switch op {
+	case ":", "=", "!=":
+		want := strings.ToLower(value)
+		match = func(s string) bool { return s == want }
+	case ":~", "~":
+		r, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		match = r.MatchString
+	default:
+		return nil, fmt.Errorf("want :, :~, =, or != after %s", key)
+	}
+	if op == "!=" {
+		m := match
+		match = func(s string) bool { return !m(s) }
+	}
+	switch key {
+	case "tld":
+		return func(d string) bool { return match(TLD(d)) }, nil
+	case "root":
+		return func(d string) bool { return match(Root(d)) }, nil
+	case "domain":
+		return match, nil
+	}
+	if op == "!=" {
+		// no label is value
+		return func(d string) bool {
+			for _, l := range strings.Split(d, ".") {
+				if !match(l) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+	return func(d string) bool {
+		for _, l := range strings.Split(d, ".") {
+			if match(l) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// numberTerm parses a term on the number of labels, or the length, of a domain.
+func numberTerm(key, op, value string) (func(string) bool, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("want a number after %s%s", key, op)
+	}
+	measure := func(d string) int { return len(d) }
+	if key == "depth" {
+		measure = func(d string) int { return strings.Count(d, ".") + 1 }
+	}
+	var compare func(int) bool
+	switch op {
+	case ":", "=":
+		compare = func(m int) bool { return m == n }
+	case "!=":
+		compare = func(m int) bool { return m != n }
+	case "<":
+		compare = func(m int) bool { return m < n }
+	case "<=":
+		compare = func(m int) bool { return m <= n }
+	case ">":
+		compare = func(m int) bool { return m > n }
+	case ">=":
+		compare = func(m int) bool { return m >= n }
+	default:
+		return nil, fmt.Errorf("want =, !=, <, <=, >, or >= after %s", key)
+	}
+	return func(d string) bool { return compare(measure(d)) }, nil
+}
@@ -0,0 +1,75 @@
+package hosts
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	// testing the terms of the query language
+	raw := []byte(`0.0.0.0 a.b.c.xyz
+0.0.0.0 tracker.example.com
+0.0.0.0 pagead2.googlesyndication.com
+0.0.0.0 x.y.pagead.googlesyndication.com
+0.0.0.0 averyveryveryverylongsubdomainname.example.org
+`)
+
+	for _, test := range []struct{ query, want string }{
+		{"tld:xyz", "a.b.c.xyz"},
+		{"tld:~^(com|org)$ depth=3", "averyveryveryverylongsubdomainname.example.org pagead2.googlesyndication.com tracker.example.com"},
+		{"root:googlesyndication.com depth>=4", "x.y.pagead.googlesyndication.com"},
+		{"len>40", "averyveryveryverylongsubdomainname.example.org"},
+		{"label:~track", "tracker.example.com"},
+		{"label:pagead", "x.y.pagead.googlesyndication.com"},
+		{"label!=example depth<4", "pagead2.googlesyndication.com"},
+		{"!root:googlesyndication.com tld!=org", "a.b.c.xyz tracker.example.com"},
+		{"domain:~^x\\. len<=40", "x.y.pagead.googlesyndication.com"},
+	} {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		h := New(Options{Query: q})
+		if err := h.LoadBytes(context.Background(), "test", raw); err != nil {
+			t.Errorf("%s: %v", test.query, err)
+			continue
+		}
+		if got := strings.Join(h.Domains(), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.query, got, test.want)
+		}
+	}
+
+	for _, query := range []string{"", "tld", "size>3", "depth>x", "label:~(", "depth~3", "tld<3"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("%q: got no error, want one", query)
+		}
+	}
+}
+
+func TestQueryOption(t *testing.T) {
+	// testing a list loaded with a query, and its summary
+	q, _ := ParseQuery("tld:com")
+	h := New(Options{Query: q})
+	err := h.LoadBytes(context.Background(), "test", []byte("0.0.0.0 aa.com bb.com cc.net\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(h.Domains()), 2; got != want {
+		t.Errorf("got %d domains, want %d", got, want)
+	}
+	if got, want := len(h.TLDs()), 1; got != want {
+		t.Errorf("got %d TLDs, want %d", got, want)
+	}
+	if !strings.Contains(h.Summary("Test"), "Query: tld:com (2 of 3 domains)") {
+		t.Errorf("got summary %q, want the query and its count", h.Summary("Test"))
+	}
+
+	// a query that matches nothing is not an empty list
+	q, _ = ParseQuery("tld:ru")
+	h = New(Options{Query: q})
+	if err := h.LoadBytes(context.Background(), "test", []byte("0.0.0.0 aa.com\n")); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+}
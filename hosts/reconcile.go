@@ -0,0 +1,97 @@
+package hosts
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+)
+
+// A Reconciliation accounts for the domains of a main list, such as an
+// amalgamated list, against the lists it was made from.
+type Reconciliation struct {
+	Main    string   // the location of the main list
+	Domains int      // the number of domains in the main list
+	Sources []string // the locations of the sources
+	Sizes   []int    // the number of domains in each source
+	Drops   []int    // the number of domains of each source not in the main list
+	Added   []string // domains in the main list, and in no source
+	Dropped []string // domains in a source, and not in the main list
+}
+
+// Reconcile finds the domains of a main list that are in none of its
+// sources, and the domains of its sources that are not in it, such as those
+// left out by a whitelist.
+func Reconcile(main *Hosts, sources []*Hosts) Reconciliation {
+	m := main.Set()
+	r := Reconciliation{Main: main.location, Domains: len(m)}
+	all := DomainSet{}
+	for _, s := range sources {
+		set := s.Set()
+		r.Sources = append(r.Sources, s.location)
+		r.Sizes = append(r.Sizes, len(set))
+		r.Drops = append(r.Drops, len(set)-set.IntersectionLen(m))
+		all = all.Union(set)
+	}
+	r.Added = m.Difference(all)
+	r.Dropped = all.Difference(m)
+	return r
+}
+
+// Write writes the counts of the reconciliation, each source with the
+// number of its domains dropped, then the domains added and dropped.
+func (r Reconciliation) Write(w io.Writer) error {
+	fmt.Fprintf(w, "Main list: %s, %s domains\n", r.Main, humanize.Comma(int64(r.Domains)))
+	fmt.Fprintf(w, "Hand additions: %s domains, in the main list and in no source\n", humanize.Comma(int64(len(r.Added))))
+	fmt.Fprintf(w, "Dropped: %s domains, in a source and not in the main list\n\n", humanize.Comma(int64(len(r.Dropped))))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tDOMAINS\tDROPPED")
+	for i, s := range r.Sources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s, humanize.Comma(int64(r.Sizes[i])), humanize.Comma(int64(r.Drops[i])))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var lines []string
+	if len(r.Added) > 0 {
+		lines = append(lines, strings.Repeat("-", 40), "Hand additions:")
+		lines = append(lines, r.Added...)
+	}
+	if len(r.Dropped) > 0 {
+		lines = append(lines, strings.Repeat("-", 40), "Dropped:")
+		lines = append(lines, r.Dropped...)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// WriteJSON writes the reconciliation as a JSON document, with counts and arrays.
+func (r Reconciliation) WriteJSON(w io.Writer) error {
+	type source struct {
+		Location string `json:"location"`
+		Domains  int    `json:"domains"`
+		Dropped  int    `json:"dropped"`
+	}
+	type part struct {
+		Count   int      `json:"count"`
+		Domains []string `json:"domains"`
+	}
+	out := struct {
+		Main    string   `json:"main"`
+		Domains int      `json:"domains"`
+		Sources []source `json:"sources"`
+		Added   part     `json:"added"`
+		Dropped part     `json:"dropped"`
+	}{r.Main, r.Domains, []source{}, part{len(r.Added), r.Added}, part{len(r.Dropped), r.Dropped}}
+	for i, s := range r.Sources {
+		out.Sources = append(out.Sources, source{s, r.Sizes[i], r.Drops[i]})
+	}
+	return writeJSON(w, out)
+}
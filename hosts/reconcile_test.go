@@ -0,0 +1,76 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReconcile(t *testing.T) {
+	// testing the hand additions and drops of a main list against its sources
+	main := New(Options{})
+	main.LoadBytes(context.Background(), "main", []byte("0.0.0.0 aa.com bb.com cc.com hand.com\n"))
+	var sources []*Hosts
+	for _, raw := range []string{"aa.com bb.com gone.com", "cc.com bb.com"} {
+		h := New(Options{})
+		h.LoadBytes(context.Background(), "source", []byte("0.0.0.0 "+raw+"\n"))
+		sources = append(sources, h)
+	}
+
+	r := Reconcile(main, sources)
+	if got, want := strings.Join(r.Added, " "), "hand.com"; got != want {
+		t.Errorf("got added %q, want %q", got, want)
+	}
+	if got, want := strings.Join(r.Dropped, " "), "gone.com"; got != want {
+		t.Errorf("got dropped %q, want %q", got, want)
+	}
+	if got, want := r.Drops[0], 1; got != want {
+		t.Errorf("got %d dropped from the first source, want %d", got, want)
+	}
+	if got, want := r.Drops[1], 0; got != want {
+		t.Errorf("got %d dropped from the second source, want %d", got, want)
+	}
+
+	var out strings.Builder
+	r.Write(&out)
+	if !strings.Contains(out.String(), "Hand additions: 1 domains") || !strings.Contains(out.String(), "Dropped:\ngone.com\n") {
+		t.Errorf("got %q", out.String())
+	}
+
+	out.Reset()
+	r.WriteJSON(&out)
+	var decoded struct{ Dropped struct{ Count int } }
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Dropped.Count, 1; got != want {
+		t.Errorf("got %d dropped in JSON, want %d", got, want)
+	}
+}
+
+func TestReconcileBuild(t *testing.T) {
+	// testing that a built list accounts for its sources, less the whitelist
+	root := filepath.Join("..", "test", "build")
+	built, err := Build(context.Background(), root, BuildOptions{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, _ := BuildSources(root, nil)
+	var sources []*Hosts
+	for _, s := range found {
+		h := New(Options{})
+		h.Load(context.Background(), filepath.Join(s.Dir, "hosts"))
+		sources = append(sources, h)
+	}
+
+	r := Reconcile(built, sources)
+	if got, want := len(r.Added), 0; got != want {
+		t.Errorf("got %d hand additions to a built list, want %d", got, want)
+	}
+	if got, want := strings.Join(r.Dropped, " "), "allowed.example.org"; !strings.Contains(got, want) {
+		t.Errorf("got dropped %q, want the whitelisted %q", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+package hosts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// A Shortcut is a short name for the location of a well-known hosts list.
+type Shortcut struct {
+	Name        string   `json:"name" yaml:"name" toml:"name"`
+	URL         string   `json:"url" yaml:"url" toml:"url"`
+	Description string   `json:"description" yaml:"description" toml:"description"`
+	Category    string   `json:"category" yaml:"category" toml:"category"`
+	Mirrors     []string `json:"mirrors,omitempty" yaml:"mirrors,omitempty" toml:"mirrors,omitempty"` // tried in order when URL fails
+}
+
+// The categories of the built-in shortcuts.
+const (
+	Amalgamated = "amalgamated"
+	SourceList  = "source"
+)
+
+// Shortcuts is a registry of shortcuts, keyed by name.
+type Shortcuts map[string]Shortcut
+
+// shortcutFile is the layout of a shortcuts file, in any of its formats.
+type shortcutFile struct {
+	Shortcuts []Shortcut `json:"shortcuts" yaml:"shortcuts" toml:"shortcuts"`
+}
+
+const githubRaw = "https://raw.githubusercontent.com/"
+
+// DefaultShortcuts returns the built-in shortcut registry.
+func DefaultShortcuts() Shortcuts {
+	s := Shortcuts{}
+	add := func(category, name, url, description string) {
+		s[name] = Shortcut{Name: name, URL: url, Description: description, Category: category, Mirrors: jsDelivr(url)}
+	}
+
+	base := githubRaw + "StevenBlack/hosts/master/"
+	add(Amalgamated, "b", base+"hosts", "Steven Black's base amalgamated list")
+	add(Amalgamated, "base", base+"hosts", "Steven Black's base amalgamated list")
+	for _, code := range VariantCodes()[1:] {
+		variant := variantPath(VariantExtensions(code)) + "hosts"
+		add(Amalgamated, code, base+variant, variant)
+	}
+
+	add(SourceList, "adaway", githubRaw+"AdAway/adaway.github.io/master/hosts.txt", "adaway.github.io")
+	add(SourceList, "add2o7net", githubRaw+"FadeMind/hosts.extras/master/add.2o7Net/hosts", "FadeMind add.2o7Net hosts")
+	add(SourceList, "adddead", githubRaw+"FadeMind/hosts.extras/master/add.Dead/hosts", "FadeMind add.Dead hosts")
+	add(SourceList, "addrisk", githubRaw+"FadeMind/hosts.extras/master/add.Risk/hosts", "FadeMind add.Risk hosts")
+	add(SourceList, "addspam", githubRaw+"FadeMind/hosts.extras/master/add.Spam/hosts", "FadeMind add.Spam hosts")
+	add(SourceList, "adguard", githubRaw+"AdguardTeam/cname-trackers/master/combined_disguised_trackers_justdomains.txt", "AdguardTeam cname-trackers")
+	add(SourceList, "baddboyz", githubRaw+"mitchellkrogza/Badd-Boyz-Hosts/master/hosts", "mitchellkrogza Badd-Boyz-Hosts")
+	add(SourceList, "clefspear", githubRaw+"Clefspeare13/pornhosts/master/0.0.0.0/hosts", "Clefspeare13 pornhosts")
+	add(SourceList, "digitalside", githubRaw+"davidonzo/Threat-Intel/master/lists/latestdomains.piHole.txt", "davidonzo Threat-Intel")
+	add(SourceList, "fakenews", githubRaw+"marktron/fakenews/master/fakenews", "marktron/fakenews")
+	add(SourceList, "hostsvn", githubRaw+"bigdargon/hostsVN/master/option/hosts-VN", "bigdargon hostsVN")
+	add(SourceList, "kadhosts", githubRaw+"PolishFiltersTeam/KADhosts/master/KADhosts.txt", "PolishFiltersTeam")
+	add(SourceList, "metamask", githubRaw+"MetaMask/eth-phishing-detect/master/src/hosts.txt", "MetaMask eth-phishing hosts")
+	add(SourceList, "mvps", "https://winhelp2002.mvps.org/hosts.txt", "winhelp2002.mvps.org")
+	add(SourceList, "orca", "https://orca.pet/notonmyshift/hosts.txt", "orca.pet notonmyshift hosts")
+	add(SourceList, "shady", githubRaw+"shreyasminocha/shady-hosts/main/hosts", "shreyasminocha shady hosts")
+	add(SourceList, "sinfonietta-gambling", githubRaw+"Sinfonietta/hostfiles/master/gambling-hosts", "Sinfonietta gambling hosts")
+	add(SourceList, "sinfonietta-porn", githubRaw+"Sinfonietta/hostfiles/master/pornography-hosts", "Sinfonietta pornography hosts")
+	add(SourceList, "sinfonietta-snuff", githubRaw+"Sinfonietta/hostfiles/master/snuff-hosts", "Sinfonietta snuff hosts")
+	add(SourceList, "sinfonietta-social", githubRaw+"Sinfonietta/hostfiles/master/social-hosts", "Sinfonietta social hosts")
+	add(SourceList, "someonewhocares", "https://someonewhocares.org/hosts/zero/hosts", "Sam Pollock someonewhocares.org")
+	add(SourceList, "stevenblack", base+"data/StevenBlack/hosts", "Steven Black ad-hoc list")
+	add(SourceList, "tiuxo-porn", githubRaw+"tiuxo/hosts/master/porn", "tiuxo porn list")
+	add(SourceList, "tiuxo-social", githubRaw+"tiuxo/hosts/master/social", "tiuxo social list")
+	add(SourceList, "tiuxo", githubRaw+"tiuxo/hosts/master/ads", "tiuxo ads list")
+	add(SourceList, "uncheckyads", githubRaw+"FadeMind/hosts.extras/master/UncheckyAds/hosts", "FadeMind UncheckyAds")
+	add(SourceList, "urlhaus", "https://urlhaus.abuse.ch/downloads/hostfile/", "urlhaus.abuse.ch")
+	add(SourceList, "yoyo", "https://pgl.yoyo.org/adservers/serverlist.php?hostformat=hosts&mimetype=plaintext&useip=0.0.0.0", "Peter Lowe yoyo.org")
+
+	return s
+}
+
+// jsDelivr returns the jsDelivr CDN mirror of a raw.githubusercontent.com
+// URL, or nothing for other URLs.
+func jsDelivr(url string) []string {
+	if !strings.HasPrefix(url, githubRaw) {
+		return nil
+	}
+	// owner/repo/branch/path becomes owner/repo@branch/path
+	parts := strings.SplitN(strings.TrimPrefix(url, githubRaw), "/", 4)
+	if len(parts) < 4 {
+		return nil
+	}
+	return []string{"https://cdn.jsdelivr.net/gh/" + parts[0] + "/" + parts[1] + "@" + parts[2] + "/" + parts[3]}
+}
+
+// ShortcutsPath returns the shortcuts file in the user configuration
+// directory, ghosts/shortcuts.json, .yaml, .yml, or .toml, whichever exists
+// first. It returns "" when there is none.
+func ShortcutsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, ext := range []string{".json", ".yaml", ".yml", ".toml"} {
+		path := filepath.Join(dir, "ghosts", "shortcuts"+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadShortcuts reads a JSON, YAML, or TOML shortcuts file, chosen by its
+// extension, and merges its entries over the built-in shortcuts. An entry
+// with the name of a built-in shortcut replaces it. An empty path yields
+// the built-in shortcuts.
+func LoadShortcuts(path string) (Shortcuts, error) {
+	s := DefaultShortcuts()
+	if path == "" {
+		return s, nil
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f shortcutFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(bytes, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bytes, &f)
+	case ".toml":
+		err = toml.Unmarshal(bytes, &f)
+	default:
+		return nil, fmt.Errorf("%s: unknown shortcuts format, want .json, .yaml, or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for i, sc := range f.Shortcuts {
+		if sc.Name == "" || sc.URL == "" {
+			return nil, fmt.Errorf("%s: shortcut %d needs a name and a url", path, i+1)
+		}
+		s[sc.Name] = sc
+	}
+	return s, nil
+}
+
+// Resolve returns the location a shortcut name stands for, or the location
+// itself when it is not a shortcut.
+func (s Shortcuts) Resolve(location string) string {
+	if sc, ok := s[location]; ok {
+		return sc.URL
+	}
+	return location
+}
+
+// Source returns the Source for a location. A shortcut with mirrors is a
+// MirrorSource that tries its URL, then each mirror in turn.
+func (s Shortcuts) Source(location string) (Source, error) {
+	sc, ok := s[location]
+	if !ok {
+		return NewSource(location)
+	}
+	if len(sc.Mirrors) == 0 {
+		return NewSource(sc.URL)
+	}
+	return MirrorSource(append([]string{sc.URL}, sc.Mirrors...)), nil
+}
+
+// Sorted returns the shortcuts ordered by category, then by name.
+func (s Shortcuts) Sorted() []Shortcut {
+	var sorted []Shortcut
+	for _, sc := range s {
+		sorted = append(sorted, sc)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Category != sorted[j].Category {
+			return sorted[i].Category < sorted[j].Category
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
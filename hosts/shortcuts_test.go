@@ -0,0 +1,86 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultShortcuts(t *testing.T) {
+	// testing the built-in shortcuts
+	s := DefaultShortcuts()
+
+	got := s.Resolve("fgps")
+	want := "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-gambling-porn-social/hosts"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got = s.Resolve("./test/hosts-multi")
+	want = "./test/hosts-multi"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestLoadShortcuts(t *testing.T) {
+	// testing user-defined shortcuts in each format
+	files := map[string]string{
+		"shortcuts.json": `{"shortcuts": [
+			{"name": "corp", "url": "https://example.com/corp", "description": "Corp list", "category": "internal"},
+			{"name": "mvps", "url": "https://example.com/mvps", "description": "mvps mirror", "category": "source"}
+		]}`,
+		"shortcuts.yaml": `shortcuts:
+  - name: corp
+    url: https://example.com/corp
+    category: internal
+  - name: mvps
+    url: https://example.com/mvps
+`,
+		"shortcuts.toml": `[[shortcuts]]
+name = "corp"
+url = "https://example.com/corp"
+category = "internal"
+
+[[shortcuts]]
+name = "mvps"
+url = "https://example.com/mvps"
+`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+
+		s, err := LoadShortcuts(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := s.Resolve("corp"); got != "https://example.com/corp" {
+			t.Errorf("%s: got %s for a new shortcut", name, got)
+		}
+		if got := s.Resolve("mvps"); got != "https://example.com/mvps" {
+			t.Errorf("%s: got %s for an overridden shortcut", name, got)
+		}
+		if got, want := len(s), len(DefaultShortcuts())+1; got != want {
+			t.Errorf("%s: got %d shortcuts, want %d", name, got, want)
+		}
+	}
+}
+
+func TestLoadShortcutsInvalid(t *testing.T) {
+	// testing shortcuts files that are rejected
+	dir := t.TempDir()
+	files := map[string]string{
+		"nameless.json": `{"shortcuts": [{"url": "https://example.com/x"}]}`,
+		"shortcuts.ini": `corp = https://example.com/corp`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := LoadShortcuts(path); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
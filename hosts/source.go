@@ -0,0 +1,255 @@
+package hosts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// A Source is somewhere a hosts list can be read from.
+type Source interface {
+	// Open the list for reading. The caller closes the returned reader.
+	Open(ctx context.Context) (io.ReadCloser, Metadata, error)
+}
+
+// Metadata describes the list a Source opened.
+type Metadata struct {
+	Location string    // where the list was read from, or the first of its mirrors
+	Modified time.Time // when the list last changed, if known
+	Status   int       // the HTTP status code, for lists fetched over HTTP
+	Mirror   string    // the location that answered, for lists with mirrors
+	Failed   []string  // the locations that failed before Mirror answered
+	Commit   string    // the commit hash, for lists read from a git repository
+}
+
+// An Opener makes a Source for a location.
+type Opener func(location string) (Source, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Opener{}
+)
+
+func init() {
+	Register("file", func(location string) (Source, error) {
+		return FileSource(strings.TrimPrefix(strings.TrimPrefix(location, "file:"), "//")), nil
+	})
+	Register("http", func(location string) (Source, error) { return HTTPSource(location), nil })
+	Register("https", func(location string) (Source, error) { return HTTPSource(location), nil })
+	Register("stdin", func(location string) (Source, error) { return StdinSource{}, nil })
+	Register("clip", func(location string) (Source, error) { return ClipboardSource{}, nil })
+}
+
+// Register makes an Opener available for locations with the given URL
+// scheme, such as "https" for "https://example.com/hosts". Registering a
+// scheme again replaces its Opener.
+func Register(scheme string, o Opener) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(scheme)] = o
+}
+
+// NewSource returns the Source for a location. Locations with a registered
+// scheme use its Opener, "-" is the standard input, and anything else is a
+// local file.
+func NewSource(location string) (Source, error) {
+	if location == "-" {
+		return StdinSource{}, nil
+	}
+	registryMu.RLock()
+	o, ok := registry[scheme(location)]
+	registryMu.RUnlock()
+	if !ok {
+		return FileSource(location), nil
+	}
+	return o(location)
+}
+
+// scheme returns the lowercase URL scheme of a location, or "" if it has
+// none. Single letters are Windows drives, not schemes.
+func scheme(location string) string {
+	i := strings.Index(location, ":")
+	if i < 2 {
+		return ""
+	}
+	s := strings.ToLower(location[:i])
+	for j, c := range s {
+		alpha := c >= 'a' && c <= 'z'
+		other := (c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.'
+		if !alpha && (j == 0 || !other) {
+			return ""
+		}
+	}
+	return s
+}
+
+// A FileSource reads a local file.
+type FileSource string
+
+func (f FileSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	file := string(f)
+	meta := Metadata{Location: file}
+	if err := ctx.Err(); err != nil {
+		return nil, meta, &LoadError{file, nil, err}
+	}
+	r, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, meta, &LoadError{file, ErrNotFound, err}
+	}
+	if err != nil {
+		return nil, meta, &LoadError{file, nil, err}
+	}
+	if fi, err := r.Stat(); err == nil {
+		meta.Modified = fi.ModTime()
+	}
+	return r, meta, nil
+}
+
+// An HTTPSource fetches a URL.
+type HTTPSource string
+
+func (u HTTPSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	url := string(u)
+	meta := Metadata{Location: url}
+	var client = http.Client{
+		Timeout: time.Duration(5000 * time.Millisecond),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, meta, &LoadError{url, nil, err}
+	}
+	resp, err := client.Do(req)
+	if err != nil && ctx.Err() != nil {
+		return nil, meta, &LoadError{url, nil, ctx.Err()}
+	}
+	if err != nil {
+		return nil, meta, &LoadError{url, ErrNetwork, err}
+	}
+	meta.Status = resp.StatusCode
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		resp.Body.Close()
+		return nil, meta, &LoadError{url, ErrNotFound, errors.New(resp.Status)}
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		resp.Body.Close()
+		return nil, meta, &LoadError{url, ErrNetwork, errors.New(resp.Status)}
+	}
+
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		meta.Modified = t
+	}
+	return networkBody{resp.Body, url}, meta, nil
+}
+
+// networkBody reports failures reading a response body as network errors.
+type networkBody struct {
+	io.ReadCloser
+	location string
+}
+
+func (b networkBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = &LoadError{b.location, ErrNetwork, err}
+	}
+	return n, err
+}
+
+// A MirrorSource tries each of its locations in turn, and reads the first
+// that answers. Its Metadata keeps the first location, so a list pinned by
+// that location verifies whichever mirror served it, and records which one
+// answered, and which failed.
+type MirrorSource []string
+
+func (m MirrorSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	if len(m) == 0 {
+		return nil, Metadata{}, &LoadError{"", ErrNotFound, errors.New("no locations to try")}
+	}
+	var failed []string
+	var last error
+	for _, location := range m {
+		if err := ctx.Err(); err != nil {
+			return nil, Metadata{Location: m[0], Failed: failed}, &LoadError{m[0], nil, err}
+		}
+		raw, meta, err := openAll(ctx, location)
+		if err != nil {
+			failed = append(failed, location)
+			last = err
+			continue
+		}
+		meta.Location = m[0]
+		meta.Mirror = location
+		meta.Failed = failed
+		return ioutil.NopCloser(bytes.NewReader(raw)), meta, nil
+	}
+	return nil, Metadata{Location: m[0], Failed: failed}, last
+}
+
+// openAll opens a location and reads all of it, so a failure part way
+// through can still fall back to another mirror.
+func openAll(ctx context.Context, location string) ([]byte, Metadata, error) {
+	src, err := NewSource(location)
+	if err != nil {
+		return nil, Metadata{Location: location}, &LoadError{location, nil, err}
+	}
+	r, meta, err := src.Open(ctx)
+	if err != nil {
+		return nil, meta, err
+	}
+	defer r.Close()
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, meta, err
+	}
+	return raw, meta, nil
+}
+
+// A StdinSource reads the standard input, until it closes or the context
+// is done.
+type StdinSource struct{}
+
+func (StdinSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	meta := Metadata{Location: "stdin"}
+	type result struct {
+		raw []byte
+		err error
+	}
+	// A read of stdin cannot be interrupted, so read it aside and stop
+	// waiting when the context is done.
+	read := make(chan result, 1)
+	go func() {
+		raw, err := ioutil.ReadAll(os.Stdin)
+		read <- result{raw, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, meta, &LoadError{meta.Location, nil, ctx.Err()}
+	case r := <-read:
+		if r.err != nil {
+			return nil, meta, &LoadError{meta.Location, nil, r.err}
+		}
+		return ioutil.NopCloser(bytes.NewReader(r.raw)), meta, nil
+	}
+}
+
+// A ClipboardSource reads the system clipboard.
+type ClipboardSource struct{}
+
+func (ClipboardSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	meta := Metadata{Location: "clipboard"}
+	clip, err := clipboard.ReadAll()
+	if err != nil {
+		return nil, meta, &LoadError{"clipboard", nil, err}
+	}
+	return ioutil.NopCloser(strings.NewReader(clip)), meta, nil
+}
@@ -0,0 +1,146 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScheme(t *testing.T) {
+	// testing URL scheme detection for the source registry
+	tests := map[string]string{
+		"https://example.com/hosts": "https",
+		"HTTP://example.com/hosts":  "http",
+		"file:///etc/hosts":         "file",
+		"clip:":                     "clip",
+		"git:/repo@HEAD:hosts":      "git",
+		"./test/hosts-multi":        "",
+		"C:\\hosts":                 "",
+		"hosts-multi":               "",
+	}
+
+	for location, want := range tests {
+		if got := scheme(location); got != want {
+			t.Errorf("%s: got scheme %q, want %q", location, got, want)
+		}
+	}
+}
+
+type stringSource string
+
+func (s stringSource) Open(ctx context.Context) (io.ReadCloser, Metadata, error) {
+	return ioutil.NopCloser(strings.NewReader(string(s))), Metadata{Location: "test:"}, nil
+}
+
+func TestRegister(t *testing.T) {
+	// testing a third party loader registered for its own scheme
+	Register("test", func(location string) (Source, error) {
+		return stringSource("0.0.0.0 aa.com bb.com\n"), nil
+	})
+
+	hf := New(Options{})
+	if err := hf.Load(context.Background(), "test:anything"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := len(hf.Domains())
+	want := 2
+
+	if got != want {
+		t.Errorf("got %d domains, want %d", got, want)
+	}
+	if hf.Location() != "test:" {
+		t.Errorf("got location %q, want %q", hf.Location(), "test:")
+	}
+}
+
+func TestFileScheme(t *testing.T) {
+	// testing file: locations
+	hf := New(Options{})
+	if err := hf.Load(context.Background(), "file:../test/hosts-plain-list"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := len(hf.Domains())
+	want := 3
+
+	if got != want {
+		t.Errorf("got %d domains, want %d", got, want)
+	}
+	if hf.Metadata().Modified.IsZero() {
+		t.Errorf("got no modification time for a file")
+	}
+}
+
+func TestMirrorSource(t *testing.T) {
+	// testing the fallback to a mirror when the primary URL fails
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/primary" {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("0.0.0.0 aa.com\n"))
+	}))
+	defer server.Close()
+
+	s := Shortcuts{}
+	s["x"] = Shortcut{Name: "x", URL: server.URL + "/primary", Mirrors: []string{"../test/no-such-hosts", server.URL + "/mirror"}}
+	src, err := s.Source("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hf := New(Options{})
+	if err := hf.LoadSource(context.Background(), src); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := hf.Location(), server.URL+"/primary"; got != want {
+		t.Errorf("got location %s, want %s", got, want)
+	}
+	if got, want := hf.Metadata().Mirror, server.URL+"/mirror"; got != want {
+		t.Errorf("got mirror %s, want %s", got, want)
+	}
+	if got, want := len(hf.Metadata().Failed), 2; got != want {
+		t.Errorf("got %d failed locations, want %d", got, want)
+	}
+	if !strings.Contains(hf.Summary("Mirrored"), "Fallback: served by "+server.URL+"/mirror") {
+		t.Errorf("got a summary that does not name the mirror that answered")
+	}
+}
+
+func TestMirrorSourceAllFail(t *testing.T) {
+	// testing that the last failure is reported when every mirror fails
+	hf := New(Options{})
+	err := hf.LoadSource(context.Background(), MirrorSource{"../test/no-such-hosts", "../test/no-such-mirror"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMirrorSourceEmpty(t *testing.T) {
+	// testing that a mirror source with no locations fails without a panic
+	hf := New(Options{})
+	err := hf.LoadSource(context.Background(), MirrorSource{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestJsDelivr(t *testing.T) {
+	// testing the jsDelivr mirror of GitHub hosted lists
+	got := DefaultShortcuts()["adaway"].Mirrors
+	want := "https://cdn.jsdelivr.net/gh/AdAway/adaway.github.io@master/hosts.txt"
+
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got mirrors %v, want %s", got, want)
+	}
+	if got := DefaultShortcuts()["mvps"].Mirrors; len(got) != 0 {
+		t.Errorf("got mirrors %v, want none", got)
+	}
+}
@@ -0,0 +1,86 @@
+package hosts
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// extensionCodes maps the letters of the shortcut codes to their extensions.
+var extensionCodes = map[rune]string{'f': "fakenews", 'g': "gambling", 'p': "porn", 's': "social"}
+
+// VariantCodes returns the shortcut codes of the base list and each of its
+// extension combinations, in the order of the upstream README table: by
+// number of extensions, then by name.
+func VariantCodes() []string {
+	codes := []string{"f", "fg", "fgp", "fgps", "fgs", "fp", "fps", "fs", "g", "gp", "gps", "gs", "p", "ps", "s"}
+	sort.SliceStable(codes, func(i, j int) bool { return len(codes[i]) < len(codes[j]) })
+	return append([]string{"base"}, codes...)
+}
+
+// VariantExtensions returns the extensions of a variant's shortcut code.
+func VariantExtensions(code string) []string {
+	if code == "base" || code == "b" {
+		return nil
+	}
+	var exts []string
+	for _, c := range code {
+		if ext, ok := extensionCodes[c]; ok {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// variantPath is where a variant lives in the StevenBlack/hosts repository.
+func variantPath(exts []string) string {
+	if len(exts) == 0 {
+		return ""
+	}
+	return "alternates/" + strings.Join(exts, "-") + "/"
+}
+
+// A VariantStat is the size of the base list, or one of its extension combinations.
+type VariantStat struct {
+	Code    string // its shortcut code
+	Domains int
+	Bytes   int
+}
+
+// WriteStatsTable writes the Markdown table of domain counts of the upstream
+// README. The first stat is the base list the others are compared to.
+func WriteStatsTable(w io.Writer, stats []VariantStat) error {
+	lines := []string{
+		"Host file recipe | Readme | Raw hosts | Unique domains | Difference from base | Size",
+		"---------------- |:------:|:---------:|:--------------:|:--------------------:|:----:",
+	}
+	base := 0
+	if len(stats) > 0 {
+		base = stats[0].Domains
+	}
+	for i, s := range stats {
+		exts := VariantExtensions(s.Code)
+		recipe := "Unified hosts = **(adware + malware)**"
+		if len(exts) > 0 {
+			recipe = "Unified hosts **+ " + strings.Join(exts, " + ") + "**"
+		}
+		path := variantPath(exts)
+		readme := "https://github.com/StevenBlack/hosts/blob/master/" + path + "readme.md"
+		raw := githubRaw + "StevenBlack/hosts/master/" + path + "hosts"
+		diff := "-"
+		if i > 0 {
+			if s.Domains-base > 0 {
+				diff = "+" + humanize.Comma(int64(s.Domains-base))
+			} else {
+				diff = humanize.Comma(int64(s.Domains - base))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s | [Readme](%s) | [link](%s) | %s | %s | %s",
+			recipe, readme, raw, humanize.Comma(int64(s.Domains)), diff, humanize.Bytes(uint64(s.Bytes))))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
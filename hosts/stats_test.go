@@ -0,0 +1,45 @@
+package hosts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariantCodes(t *testing.T) {
+	// testing the order and extensions of the variants
+	codes := VariantCodes()
+
+	if got, want := len(codes), 16; got != want {
+		t.Fatalf("got %d variants, want %d", got, want)
+	}
+	if got, want := strings.Join(codes[:6], " "), "base f g p s fg"; got != want {
+		t.Errorf("got variants %s, want %s", got, want)
+	}
+	if got := VariantExtensions("base"); len(got) != 0 {
+		t.Errorf("got extensions %v for base, want none", got)
+	}
+	if got, want := strings.Join(VariantExtensions("fgps"), "-"), "fakenews-gambling-porn-social"; got != want {
+		t.Errorf("got extensions %s, want %s", got, want)
+	}
+}
+
+func TestWriteStatsTable(t *testing.T) {
+	// testing the Markdown statistics table
+	stats := []VariantStat{
+		{Code: "base", Domains: 100000, Bytes: 3000000},
+		{Code: "fg", Domains: 102500, Bytes: 3100000},
+	}
+
+	var b strings.Builder
+	WriteStatsTable(&b, stats)
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+
+	if got, want := len(lines), 4; got != want {
+		t.Fatalf("got %d lines, want %d", got, want)
+	}
+	for _, want := range []string{"Unified hosts **+ fakenews + gambling**", "102,500", "+2,500", "alternates/fakenews-gambling/hosts"} {
+		if !strings.Contains(lines[3], want) {
+			t.Errorf("got row without %q: %s", want, lines[3])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// ShrinkWarning is the fraction of its domains a source may lose between two
+// surveys before the survey warns about it.
+const ShrinkWarning = 0.25
+
+// A SurveyResult is the health of one shortcut's source.
+type SurveyResult struct {
+	Shortcut Shortcut
+	Status   int       // the HTTP status code, or 0 for other sources
+	Bytes    int       // the size of the list
+	Domains  int       // the number of domains in the list
+	Updated  time.Time // when the list last changed, if known
+	Previous int       // the number of domains at the previous survey, or 0
+	Err      error     // why the list failed to load, if it did
+}
+
+// Shrank reports whether the source lost more than ShrinkWarning of its
+// domains since the previous survey.
+func (r SurveyResult) Shrank() bool {
+	return r.Err == nil && r.Previous > 0 && float64(r.Domains) < float64(r.Previous)*(1-ShrinkWarning)
+}
+
+// Survey loads every shortcut concurrently, at most workers at a time, and
+// reports the health of each. Previous holds the domain counts of an earlier
+// survey, keyed by shortcut name. The results are in the order of shortcuts.
+func Survey(ctx context.Context, shortcuts []Shortcut, previous map[string]int, workers int) []SurveyResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]SurveyResult, len(shortcuts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = survey(ctx, shortcuts[i], previous[shortcuts[i].Name])
+			}
+		}()
+	}
+	for i := range shortcuts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func survey(ctx context.Context, sc Shortcut, previous int) SurveyResult {
+	h := New(Options{})
+	err := h.Load(ctx, sc.URL)
+	return SurveyResult{
+		Shortcut: sc,
+		Status:   h.Metadata().Status,
+		Bytes:    len(h.Raw()),
+		Domains:  len(h.Domains()),
+		Updated:  h.Updated(),
+		Previous: previous,
+		Err:      err,
+	}
+}
+
+// WriteSurvey writes survey results as an aligned table. The age of each
+// list is reckoned from now.
+func WriteSurvey(w io.Writer, results []SurveyResult, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tSIZE\tDOMAINS\tUPDATED\tDAYS\tWARNING")
+	for _, r := range results {
+		status, updated, days, warning := "-", "-", "-", ""
+		if r.Status != 0 {
+			status = strconv.Itoa(r.Status)
+		}
+		if !r.Updated.IsZero() {
+			updated = r.Updated.Format("2006-01-02")
+			days = strconv.Itoa(int(now.Sub(r.Updated).Hours() / 24))
+		}
+		switch {
+		case r.Err != nil:
+			warning = r.Err.Error()
+		case r.Shrank():
+			warning = fmt.Sprintf("shrank %.0f%% since the last survey (%s)", 100*(1-float64(r.Domains)/float64(r.Previous)), humanize.Comma(int64(r.Previous)))
+		}
+		fmt.Fprintln(tw, r.Shortcut.Name+"\t"+status+"\t"+humanize.Bytes(uint64(r.Bytes))+"\t"+humanize.Comma(int64(r.Domains))+"\t"+updated+"\t"+days+"\t"+warning)
+	}
+	return tw.Flush()
+}
+
+// ReadSurveyCounts reads the domain counts saved by WriteSurveyCounts. A
+// missing file yields no counts.
+func ReadSurveyCounts(path string) (map[string]int, error) {
+	counts := map[string]int{}
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return counts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return counts, json.Unmarshal(bytes, &counts)
+}
+
+// WriteSurveyCounts saves the domain counts of the sources that loaded, for
+// the next survey to compare against.
+func WriteSurveyCounts(path string, results []SurveyResult) error {
+	counts, err := ReadSurveyCounts(path)
+	if err != nil {
+		counts = map[string]int{}
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			counts[r.Shortcut.Name] = r.Domains
+		}
+	}
+	bytes, err := json.MarshalIndent(counts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bytes, 0644)
+}
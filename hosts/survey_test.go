@@ -0,0 +1,92 @@
+package hosts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// surveyServer serves hosts fixtures in place of the real shortcut URLs.
+func surveyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dated":
+			w.Write([]byte("# Title: dated\n# Date: 19 October 2026 05:11:19 (UTC)\n\n0.0.0.0 aa.com\n0.0.0.0 bb.com\n0.0.0.0 cc.com\n"))
+		case "/modified":
+			w.Header().Set("Last-Modified", "Sat, 10 Oct 2026 00:00:00 GMT")
+			w.Write([]byte("aa.com\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestSurvey(t *testing.T) {
+	// testing the survey of shortcuts against a local fixture server
+	server := surveyServer()
+	defer server.Close()
+
+	shortcuts := []Shortcut{
+		{Name: "dated", URL: server.URL + "/dated"},
+		{Name: "modified", URL: server.URL + "/modified"},
+		{Name: "gone", URL: server.URL + "/gone"},
+	}
+	previous := map[string]int{"dated": 3, "modified": 10}
+
+	results := Survey(context.Background(), shortcuts, previous, 2)
+
+	if got, want := results[0].Domains, 3; got != want {
+		t.Errorf("got %d domains, want %d", got, want)
+	}
+	if got, want := results[0].Updated, time.Date(2026, 10, 19, 5, 11, 19, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got header date %v, want %v", got, want)
+	}
+	if got, want := results[1].Updated, time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got Last-Modified %v, want %v", got, want)
+	}
+	if results[0].Shrank() || !results[1].Shrank() {
+		t.Errorf("got shrank %v and %v, want false and true", results[0].Shrank(), results[1].Shrank())
+	}
+	if got, want := results[2].Status, http.StatusNotFound; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+	if results[2].Err == nil {
+		t.Errorf("got no error for a missing source")
+	}
+
+	var b strings.Builder
+	WriteSurvey(&b, results, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC))
+	table := b.String()
+	for _, want := range []string{"2026-10-19", "shrank 90%", "404 Not Found"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("got table without %q:\n%s", want, table)
+		}
+	}
+}
+
+func TestSurveyCounts(t *testing.T) {
+	// testing that survey counts persist between surveys
+	path := filepath.Join(t.TempDir(), "survey.json")
+	results := []SurveyResult{
+		{Shortcut: Shortcut{Name: "a"}, Domains: 5},
+		{Shortcut: Shortcut{Name: "b"}, Err: ErrNetwork},
+	}
+	if err := WriteSurveyCounts(path, results); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ReadSurveyCounts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(counts), 1; got != want {
+		t.Errorf("got %d counts, want %d", got, want)
+	}
+	if got, want := counts["a"], 5; got != want {
+		t.Errorf("got %d domains, want %d", got, want)
+	}
+}
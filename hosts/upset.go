@@ -0,0 +1,163 @@
+package hosts
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+)
+
+// A Signature is a combination of lists, and the domains found in exactly
+// those lists.
+type Signature struct {
+	Lists   []int // the indexes of the lists, in order
+	Domains DomainSet
+}
+
+// A Contribution assigns every domain of several lists to its signature: the
+// exact combination of lists it is found in.
+type Contribution struct {
+	Names      []string
+	Sizes      []int       // the number of domains in each list
+	Exclusive  []int       // the number of domains found only in each list
+	Signatures []Signature // largest first
+}
+
+// NewContribution computes the signatures of the domains of lists, named by names.
+func NewContribution(names []string, lists []*Hosts) Contribution {
+	c := Contribution{Names: names, Sizes: make([]int, len(lists)), Exclusive: make([]int, len(lists))}
+	domains := make([]DomainSet, len(lists))
+	for i, h := range lists {
+		domains[i] = h.Set()
+		c.Sizes[i] = len(domains[i])
+	}
+
+	// merge the sorted lists, noting which of them hold each domain
+	shared := map[string][]string{}
+	next := make([]int, len(lists))
+	key := make([]byte, len(lists))
+	for {
+		least, found := "", false
+		for i, d := range domains {
+			if next[i] < len(d) && (!found || d[next[i]] < least) {
+				least, found = d[next[i]], true
+			}
+		}
+		if !found {
+			break
+		}
+		for i, d := range domains {
+			key[i] = '0'
+			if next[i] < len(d) && d[next[i]] == least {
+				key[i] = '1'
+				next[i]++
+			}
+		}
+		// the merge visits the domains in order, so each set stays sorted
+		shared[string(key)] = append(shared[string(key)], least)
+	}
+
+	for k, d := range shared {
+		s := Signature{Domains: DomainSet(d)}
+		for i := range k {
+			if k[i] == '1' {
+				s.Lists = append(s.Lists, i)
+			}
+		}
+		if len(s.Lists) == 1 {
+			c.Exclusive[s.Lists[0]] = len(d)
+		}
+		c.Signatures = append(c.Signatures, s)
+	}
+	sort.Slice(c.Signatures, func(i, j int) bool {
+		a, b := c.Signatures[i], c.Signatures[j]
+		if len(a.Domains) != len(b.Domains) {
+			return len(a.Domains) > len(b.Domains)
+		}
+		return c.label(a) < c.label(b)
+	})
+	return c
+}
+
+// Covered returns the names of the lists whose every domain is in another list.
+func (c Contribution) Covered() []string {
+	covered := []string{}
+	for i, n := range c.Exclusive {
+		if n == 0 {
+			covered = append(covered, c.Names[i])
+		}
+	}
+	return covered
+}
+
+// label names the lists of a signature.
+func (c Contribution) label(s Signature) string {
+	var names []string
+	for _, i := range s.Lists {
+		names = append(names, c.Names[i])
+	}
+	return strings.Join(names, " + ")
+}
+
+// Write writes the exclusive contribution of each list, then the top
+// largest signatures, or all of them when top is 0.
+func (c Contribution) Write(w io.Writer, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LIST\tDOMAINS\tEXCLUSIVE")
+	for i, name := range c.Names {
+		note := ""
+		if c.Exclusive[i] == 0 {
+			note = " (covered by the other lists)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s%s\n", name, humanize.Comma(int64(c.Sizes[i])), humanize.Comma(int64(c.Exclusive[i])), note)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DOMAINS\tFOUND IN EXACTLY")
+	for i, s := range c.Signatures {
+		if top > 0 && i == top {
+			fmt.Fprintf(tw, "...\t%s more combinations\n", humanize.Comma(int64(len(c.Signatures)-top)))
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", humanize.Comma(int64(len(s.Domains))), c.label(s))
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the contribution as a JSON document, with every signature
+// and its domains.
+func (c Contribution) WriteJSON(w io.Writer) error {
+	type list struct {
+		Name      string `json:"name"`
+		Domains   int    `json:"domains"`
+		Exclusive int    `json:"exclusive"`
+	}
+	type signature struct {
+		Lists   []string `json:"lists"`
+		Count   int      `json:"count"`
+		Domains []string `json:"domains"`
+	}
+	out := struct {
+		Lists      []list      `json:"lists"`
+		Covered    []string    `json:"covered"`
+		Signatures []signature `json:"signatures"`
+	}{Lists: []list{}, Covered: c.Covered(), Signatures: []signature{}}
+	for i, name := range c.Names {
+		out.Lists = append(out.Lists, list{name, c.Sizes[i], c.Exclusive[i]})
+	}
+	for _, s := range c.Signatures {
+		var names []string
+		for _, i := range s.Lists {
+			names = append(names, c.Names[i])
+		}
+		out.Signatures = append(out.Signatures, signature{names, len(s.Domains), s.Domains})
+	}
+	return writeJSON(w, out)
+}
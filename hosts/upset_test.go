@@ -0,0 +1,64 @@
+package hosts
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContribution(t *testing.T) {
+	// testing the exclusive contribution of each list, and the signatures of its domains
+	var lists []*Hosts
+	for _, raw := range []string{"aa.com bb.com cc.com dd.com", "cc.com dd.com", "dd.com ee.com ff.com"} {
+		h := New(Options{})
+		h.LoadBytes(context.Background(), "test", []byte("0.0.0.0 "+raw+"\n"))
+		lists = append(lists, h)
+	}
+
+	c := NewContribution([]string{"a", "b", "c"}, lists)
+	for i, want := range []int{2, 0, 2} {
+		if got := c.Exclusive[i]; got != want {
+			t.Errorf("got %d exclusive to %s, want %d", got, c.Names[i], want)
+		}
+	}
+	if got, want := strings.Join(c.Covered(), " "), "b"; got != want {
+		t.Errorf("got covered %q, want %q", got, want)
+	}
+
+	var got []string
+	for _, s := range c.Signatures {
+		got = append(got, c.label(s)+"="+strings.Join(s.Domains, " "))
+	}
+	if got, want := strings.Join(got, ", "), "a=aa.com bb.com, c=ee.com ff.com, a + b=cc.com, a + b + c=dd.com"; got != want {
+		t.Errorf("got signatures %q, want %q", got, want)
+	}
+
+	var out strings.Builder
+	c.Write(&out, 2)
+	if !strings.Contains(out.String(), "2 more combinations") {
+		t.Errorf("got %q, want the combinations beyond the top 2 left out", out.String())
+	}
+
+	out.Reset()
+	c.WriteJSON(&out)
+	var decoded struct {
+		Signatures []struct {
+			Lists   []string
+			Count   int
+			Domains []string
+		}
+	}
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(decoded.Signatures), 4; got != want {
+		t.Fatalf("got %d signatures in JSON, want %d", got, want)
+	}
+	if got, want := strings.Join(decoded.Signatures[0].Domains, " "), "aa.com bb.com"; got != want {
+		t.Errorf("got domains %q in JSON, want %q", got, want)
+	}
+	if got, want := decoded.Signatures[0].Count, 2; got != want {
+		t.Errorf("got count %d in JSON, want %d", got, want)
+	}
+}
@@ -1,622 +1,847 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"net"
-	"net/http"
+	"io"
 	"os"
-	"regexp"
-	"sort"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/StevenBlack/ghosts/hosts"
 	"github.com/atotto/clipboard"
 	"github.com/dustin/go-humanize"
-	"github.com/thoas/go-funk"
 )
 
 // Update the version # before every release.
 const VERSION = "v0.3"
 
 // Expose the command line flags we support
-var mainHosts, compareHosts, ipLocalhost string
-var addDefaults, alphaSort, output, plainOutput, stats, intersectionList, tld, noheader, sysclipboard, uniquelist, version, root bool
+var mainHosts, compareHosts, ipLocalhost, lockPath, shortcutsPath, buildRoot, buildExtensions, whitelistPath, outPath, historyLocation, outputFormat, blameDomain, query, level string
+var timeout time.Duration
+var top int
+var addDefaults, alphaSort, copyResult, count, coverage, delta, diffMode, output, plainOutput, stats, intersectionList, tld, noheader, sysclipboard, uniquelist, matrix, reconcile, upset, version, root, verifyLock, updateLock, list, surveyShortcuts, readmeTable bool
 
-type Thingtally struct {
-	thing string
-	tally int
-}
+func FlagSet() {
+	defaultMainHosts := "base"
+	flag.StringVar(&blameDomain, "blame", "", `Show the commits that added a domain to the -m list, or removed it.
+The -m list is a git location, git:<repo>@<rev>:<path>.`)
+	flag.StringVar(&buildRoot, "build", "", `Build an amalgamated list from a local StevenBlack/hosts checkout,
+its data/<source>/hosts files, and the extensions chosen with -ext`)
+	flag.StringVar(&compareHosts, "c", "", `Hosts list to compare.
+A shortcut code, full URL, clip: for the clipboard, - for stdin, or a local file.
+Use the -m option for the main comparison list.
+Use the -clip option to use what is on the system clipboard.
 
-// A Hosts struct holds all the facets of a collection of hosts.
-type Hosts struct {
-	Raw          []byte
-	Location     string
-	Header       []string
-	Domains      []string
-	TLDs         map[string]int
-	TLDtallies   []Thingtally
-	Roots        map[string]int
-	Roottallies  []Thingtally
-	Duplicates   []string
-	Intersection []string
-	Unique       []string
-}
+Shortcut codes
+==============
+The following shortcut codes can be used to select among preset main lists.
+Use -list to show the resolved registry, including your own shortcuts.
+`+shortcutHelp(hosts.DefaultShortcuts()))
+	flag.BoolVar(&sysclipboard, "clip", false, "The comparison hosts are in the system clipboard, as with -c clip:")
+	flag.BoolVar(&copyResult, "copy", false, `Copy the results to the system clipboard too: the processed list,
+the summaries, the comparison, or whatever else is written out`)
+	flag.BoolVar(&count, "count", false, "Print only the number of domains in the -m list, after any -query")
+	flag.BoolVar(&coverage, "coverage", false, `Show which domains of the -c list the -m list blocks exactly, which it
+blocks through a parent domain, as wildcard blocking would, and which it
+does not block; with -intersection, list the exact matches too`)
+	flag.BoolVar(&addDefaults, "d", false, "Include default hosts at the top of file.")
+	flag.BoolVar(&delta, "delta", false, `Line up the TLD tallies, with -tld, or the root domain tallies, with -root,
+of the -m and -c lists, or both without either, and show how each changed,
+the largest change first, marking those found in only one list`)
+	flag.BoolVar(&diffMode, "diff", false, `Show the domains only in the -m list (-), only in the -c list (+),
+and, with -intersection, in both ( ), in the style of a unified diff`)
+	flag.StringVar(&buildExtensions, "ext", "", "Comma-separated extensions to add with -build: fakenews, gambling, porn, social")
+	flag.StringVar(&outputFormat, "format", "", `The format of -history output, csv or json (default csv),
+of -blame, -coverage, -diff, and -reconcile output, text or json (default text),
+of the comparison of -m and -c lists, text, lines, csv, or json (default text),
+of -delta, -level, and -matrix output, text, csv, or json (default text),
+or of -upset output, text or json (default text)`)
+	flag.BoolVar(&intersectionList, "intersection", false, "Return the list of intersection hosts? (default false)")
+	flag.BoolVar(&uniquelist, "unique", false, "List the unique domains in the comparison list")
+	flag.StringVar(&level, "level", "exact", `The granularity of the comparison of the -m and -c lists: exact hostnames,
+root domains (eTLD+1, such as example.co.uk), or TLDs. At root or tld, both
+lists are collapsed before they are intersected, and each shared root or TLD
+is shown with the number of hostnames each list has under it`)
+	flag.BoolVar(&list, "list", false, "List the resolved shortcut registry, built-in and user-defined")
+	flag.StringVar(&lockPath, "lock", "ghosts.lock", "The lockfile holding the pinned size and SHA-256 of each source")
+	flag.BoolVar(&updateLock, "updatelock", false, "Refresh the lockfile pins for the sources loaded, and show what changed")
+	flag.BoolVar(&verifyLock, "verify", false, "Fail when a loaded source does not match its lockfile pin")
+	flag.StringVar(&historyLocation, "history", "", `Write the timeline of domain and TLD counts, additions, and removals,
+across the commits of a local git repository that touched a file.
+A git location, git:<repo>@<rev>:<path>, walked back from <rev>.`)
+	flag.StringVar(&ipLocalhost, "ip", "0.0.0.0", "Localhost IP address")
+	flag.BoolVar(&matrix, "matrix", false, `Show how much each pair of the lists named after the flags have in common:
+the domains they share, their Jaccard similarity, and the percentage of each in the other`)
+	flag.StringVar(&mainHosts, "m", defaultMainHosts, `The main list of hosts to analyze, or serve as a basis for comparison.
+A shortcut code, a full URL, clip: for the clipboard, - for stdin, or a local file.
+See the -c flag for the list of shortcut codes.`)
+	flag.BoolVar(&noheader, "noheader", false, "Remove the file header from output? (default false)")
+	flag.BoolVar(&output, "o", false, "Return the list of hosts? (default false)")
+	flag.StringVar(&outPath, "out", "", "Write the output to this file instead of stdout")
+	flag.BoolVar(&plainOutput, "p", false, "Return a plain output list of hosts, with no IP address prefix? (default false)")
+	flag.StringVar(&query, "query", "", `Keep only the domains of the -m and -c lists, or of an eval result,
+that match a query, for output, counts, tallies, and comparisons. Terms, separated by spaces, must
+all match: tld:xyz, root:example.com, label:track, domain:ads.example.com,
+depth>=4, len>40. tld, root, label, and domain take a regular expression
+after :~, as in label:~track; depth and len compare with =, !=, <, <=, >,
+or >=; != and a leading ! negate a term.`)
+	flag.BoolVar(&readmeTable, "readme", false, `Write the Markdown table of domain counts of the base list and each
+combination of its extensions, as in the StevenBlack/hosts readme.
+With -build, the lists are built locally rather than downloaded.`)
+	flag.BoolVar(&reconcile, "reconcile", false, `Account for the domains of the -m list against its sources, the lists named
+after the flags: show the domains in the -m list and in no source, the hand
+additions, and those in a source and not in the -m list, the dropped`)
+	flag.BoolVar(&alphaSort, "s", false, "Sort the hosts? (default false)")
+	flag.StringVar(&shortcutsPath, "shortcuts", "", `A JSON, YAML, or TOML file of shortcuts, merged over the built-in set.
+(default ghosts/shortcuts.json, .yaml, or .toml in the user config directory)`)
+	flag.BoolVar(&stats, "stats", true, "display stats?")
+	flag.BoolVar(&surveyShortcuts, "survey", false, "Load every shortcut, and report the health of each source")
+	flag.IntVar(&top, "top", 20, "The number of rows of -delta, -level, and -upset output, or 0 for all")
+	flag.BoolVar(&tld, "tld", false, "Return the list of TLD and their tally (default false)")
+	flag.BoolVar(&root, "root", false, "Return the list of root domains and their tally (default false)")
+	flag.DurationVar(&timeout, "timeout", 0, "A deadline for the whole run, like 30s or 2m (default none)")
+	flag.BoolVar(&upset, "upset", false, `Show how many domains each of the lists named after the flags has that no
+other has, flag those covered by the others, and count the domains found in
+exactly each combination of the lists, largest first`)
+	flag.BoolVar(&version, "v", false, "Return the current version")
+	flag.StringVar(&whitelistPath, "whitelist", "", "Domains, with their subdomains, to leave out of a -build (default <build>/whitelist)")
+	flag.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), `Usage of %s:
+  %s [flags]
+  %s [flags] eval <expression> [flags]
+    	Use the list that a set expression evaluates to as the -m list.
+    	| is union, & intersection, and - difference, applied left to right;
+    	parentheses group. Operands are lists, as for -m, or predicates, the
+    	terms of a -query such as tld:ru or depth>=4, that filter the lists they
+    	are intersected with or subtracted from. Quote operands holding spaces,
+    	(, ), |, or &.
+`, name, name, name)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-// Reset the Hosts structure to an initial, unloaded state.
-func (h *Hosts) Reset() bool {
-	// zero everything
-	h.Raw = []byte{}
-	h.Location = ""
-	h.Header = []string{}
-	h.Domains = []string{}
-	h.TLDs = map[string]int{}
-	h.TLDtallies = []Thingtally{}
-	h.Roots = map[string]int{}
-	h.Roottallies = []Thingtally{}
-	h.Duplicates = []string{}
-	h.Intersection = []string{}
-	h.Unique = []string{}
-
-	return true
+	// ghosts [flags] eval <expression> [flags]
+	if flag.Arg(0) == "eval" {
+		if flag.NArg() < 2 || len(flag.Arg(1)) == 0 {
+			checkError(usagef("eval: want an expression, like \"(base | adaway) - allow.txt\""))
+		}
+		expression = flag.Arg(1)
+		flag.CommandLine.Parse(flag.Args()[2:])
+	}
 }
 
-// summarize the hosts
-func (h *Hosts) Summary(prefix string) string {
-	var summary []string
-	sepLen := 40
-
-	summary = append(summary, strings.Repeat("-", sepLen))
-	summary = append(summary, prefix+" summary:")
-	summary = append(summary, strings.Repeat("-", sepLen))
-	summary = append(summary, "Location: "+h.Location)
-	summary = append(summary, "Domains: "+humanize.Comma(int64(len(h.Domains))))
-	summary = append(summary, "Bytes: "+humanize.Bytes(uint64(int64(len(h.Raw)))))
-	if tld {
-		var s []string
-		for _, t := range h.TLDtallies {
-			s = append(s, t.thing+": "+humanize.Comma(int64(t.tally)))
-		}
-		summary = append(summary, "TLD tally:  ("+humanize.Comma(int64(len(s)))+" unique TLD)\n   "+strings.Join(s, "\n   "))
-		summary = append(summary, strings.Repeat("-", sepLen))
+// expression is the set expression of the eval command, evaluated in place
+// of the -m list.
+var expression string
+
+func main() {
+
+	FlagSet()
+	if copyResult {
+		stdout = io.MultiWriter(os.Stdout, &copied)
 	}
-	if root {
-		var s []string
-		for _, t := range h.Roottallies {
-			s = append(s, t.thing+": "+humanize.Comma(int64(t.tally)))
-		}
-		summary = append(summary, "Root domain tally:  ("+humanize.Comma(int64(len(s)))+" unique root domais)\n   "+strings.Join(s, "\n   "))
-		summary = append(summary, strings.Repeat("-", sepLen))
+
+	if len(shortcutsPath) == 0 {
+		shortcutsPath = hosts.ShortcutsPath()
 	}
+	var err error
+	shortcuts, err = hosts.LoadShortcuts(shortcutsPath)
+	checkError(err)
 
-	return strings.Join(summary[:], "\n")
-}
+	if version {
+		fmt.Fprintln(stdout, "The current version is:", VERSION)
+		done()
+	}
 
-func (h *Hosts) process() []string {
-	// make a slice with the lines from the Raw domains
-	slc := strings.Split(string(h.Raw), "\n")
+	if list {
+		listShortcuts(shortcuts)
+		done()
+	}
 
-	// Step: preserve the header
-	for i := range slc {
-		tst := strings.TrimSpace(slc[i])
-		if strings.HasPrefix(tst, "#") || len(tst) == 0 {
-			h.Header = append(h.Header, slc[i])
-		} else {
-			break
-		}
+	// The first interrupt cancels the context; stop catching signals then,
+	// so a second one kills us.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		stop()
+	}()
+	ctx := sigCtx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	// Step: basic cleanup
-	for i := range slc {
-		// remove embedded comments
-		slc[i] = strings.Split(slc[i], "#")[0]
+	if surveyShortcuts {
+		survey(ctx, shortcuts)
+		done()
+	}
 
-		// remove all extra whitespace
-		words := strings.Fields(slc[i])
-		slc[i] = strings.Join(words, " ")
+	if len(historyLocation) > 0 {
+		history(ctx)
+		done()
+	}
 
-		// lowercase everything
-		slc[i] = strings.ToLower(slc[i])
+	if len(blameDomain) > 0 {
+		blame(ctx)
+		done()
 	}
 
-	// Step: discard blank lines
-	slc = h.filter(slc, h.notEmpty)
+	if readmeTable {
+		readme(ctx)
+		done()
+	}
 
-	// step: line match regex for ip address, domain, or host
-	// This regex matches domain, or host
-	r, _ := regexp.Compile("((^(?:[a-z_0-9](?:[a-z_0-9-]{0,61}[a-z_0-9])?\\.)+[a-z_0-9][a-z_0-9-]{0,61}[a-z_0-9]$)|((^(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?))(\\s+((?:[a-z_0-9](?:[a-z_0-9-]{0,61}[a-z_0-9])?\\.)+[a-z_0-9][a-z_0-9-]{0,61}[a-z_0-9]\\s*)+$)))")
-	var matchSlice []string
-	for i := range slc {
-		if r.MatchString(slc[i]) {
-			words := strings.Fields(slc[i])
-			if net.ParseIP(words[0]) == nil {
-				// no IP segment - handle case of multi-host line
-				newSlice := strings.Split(slc[i], " ")
-				matchSlice = append(matchSlice, newSlice...)
-			} else {
-				// remove the IP segment
-				newSlice := strings.Split(strings.Join(words[1:], " "), " ")
-				matchSlice = append(matchSlice, newSlice...)
-			}
-		}
+	if len(buildRoot) > 0 {
+		build(ctx)
+		done()
 	}
-	slc = matchSlice
 
-	// we could bail at this juncture
-	if len(slc) == 0 {
-		return slc
+	if verifyLock || updateLock {
+		var err error
+		lock, err = hosts.ReadLockfile(lockPath)
+		checkError(err)
 	}
 
-	// regular string sort for deduplication
-	sort.Sort(sort.StringSlice(slc))
+	if matrix {
+		overlap(ctx, flag.Args())
+		done()
+	}
 
-	// deduplicate
-	j := 0
-	for i := 1; i < len(slc); i++ {
-		if slc[j] == slc[i] {
-			h.Duplicates = append(h.Duplicates, slc[j])
-			continue
-		}
-		j++
-		slc[j] = slc[i]
-	}
-	slc = slc[:j+1]
-
-	// tally TLDs
-	if tld {
-		h.TLDs = make(map[string]int)
-		h.TLDtallies = []Thingtally{}
-		m := map[string]int{}
-		n := map[int][]string{}
-		for i := range slc {
-			ss := strings.Split(slc[i], ".")
-			if len(ss) > 1 {
-				s := ss[len(ss)-1]
-				_, ok := m[s]
-				if ok {
-					m[s] = m[s] + 1
-				} else {
-					m[s] = 1
-				}
-			}
-		}
-		var a []int
-		for k, v := range m {
-			n[v] = append(n[v], k)
-		}
-		for k := range n {
-			a = append(a, k)
-		}
+	if reconcile {
+		reconciliation(ctx, flag.Args())
+		done()
+	}
 
-		sort.Sort(sort.Reverse(sort.IntSlice(a)))
+	if upset {
+		contribution(ctx, flag.Args())
+		done()
+	}
 
-		for _, k := range a {
-			for _, s := range n[k] {
-				h.TLDs[s] = k
-				h.TLDtallies = append(h.TLDtallies, Thingtally{s, k})
-			}
+	if _, err := hosts.ParseLevel(level); err != nil {
+		checkError(usagef("-level %w", err))
+	}
+
+	opts := hosts.Options{Sort: alphaSort, TLD: tld, Root: root}
+	if delta {
+		// the deltas take the place of the tallies in the summaries
+		opts.TLD, opts.Root = false, false
+	}
+	if len(query) > 0 {
+		opts.Query, err = hosts.ParseQuery(query)
+		if err != nil {
+			checkError(usageError{err})
 		}
 	}
 
-	// tally Roots
-	if root {
-		h.Roots = make(map[string]int)
-		h.Roottallies = []Thingtally{}
-		m := map[string]int{}
-		n := map[int][]string{}
-		for i := range slc {
-			ss := strings.Split(slc[i], ".")
-			if len(ss) > 1 {
-				s := ss[len(ss)-2] + "." + ss[len(ss)-1]
-				_, ok := m[s]
-				if ok {
-					m[s] = m[s] + 1
-				} else {
-					m[s] = 1
-				}
+	hf1 := hosts.New(opts)
+	if len(expression) > 0 {
+		hf1, err = hosts.Eval(ctx, expression, func(ctx context.Context, location string) (*hosts.Hosts, error) {
+			h := hosts.New(hosts.Options{})
+			err := load(ctx, h, location)
+			if err == nil {
+				pin(h)
 			}
+			return h, err
+		}, opts)
+		// an error that is not a failure to load a list is in the expression
+		var le *hosts.LoadError
+		if err != nil && !errors.As(err, &le) {
+			err = usageError{err}
 		}
-		var a []int
-		for k, v := range m {
-			n[v] = append(n[v], k)
-		}
-		for k := range n {
-			a = append(a, k)
-		}
+		checkError(err)
+	} else {
+		checkError(load(ctx, hf1, mainHosts))
+		pin(hf1)
+	}
+	if count {
+		fmt.Fprintln(stdout, len(hf1.Domains()))
+		done()
+	}
+	write(hf1)
 
-		sort.Sort(sort.Reverse(sort.IntSlice(a)))
+	// machine-readable comparisons on stdout are not mixed with summaries
+	summarize := stats && !output && (len(outPath) > 0 || outputFormat == "" || outputFormat == "text")
 
-		for _, k := range a {
-			for _, s := range n[k] {
-				h.Roots[s] = k
-				h.Roottallies = append(h.Roottallies, Thingtally{s, k})
-			}
+	if summarize {
+		if len(expression) > 0 {
+			fmt.Fprintln(stdout, hf1.Summary("Evaluated hosts"))
+		} else {
+			fmt.Fprintln(stdout, hf1.Summary("Base hosts file"))
 		}
 	}
-	// custom domain sorting
-	if alphaSort {
-		sort.Sort(domainSort(slc))
+
+	if len(compareHosts) == 0 && sysclipboard {
+		compareHosts = "clip:"
+	}
+	if len(compareHosts) > 0 {
+		comparison(ctx, opts, hf1, summarize)
 	}
 
-	// Stash our slice of domains.
-	h.Domains = slc
+	done()
+}
 
-	if output {
-		// first, the header
-		if !noheader {
-			for i := range h.Header {
-				fmt.Println(h.Header[i])
-			}
+// comparison loads the -c list, or the clipboard with -clip, and compares
+// the base list with it.
+func comparison(ctx context.Context, opts hosts.Options, hf1 *hosts.Hosts, summarize bool) {
+	hf2 := hosts.New(opts)
+	checkError(load(ctx, hf2, compareHosts))
+	pin(hf2)
+	write(hf2)
+	if summarize {
+		if compareHosts == "clip:" {
+			fmt.Fprintln(stdout, hf2.Summary("Compared hosts from clipboard"))
+		} else {
+			fmt.Fprintln(stdout, hf2.Summary("Compared hosts file"))
 		}
+	}
 
-		// add defaults
-		if addDefaults && !plainOutput {
-			fmt.Println("127.0.0.1 localhost")
-			fmt.Println("127.0.0.1 localhost.localdomain")
-			fmt.Println("127.0.0.1 local")
-			fmt.Println("255.255.255.255 broadcasthost")
-			fmt.Println("::1 localhost")
-			fmt.Println("::1 ip6-localhost")
-			fmt.Println("::1 ip6-loopback")
-			fmt.Println("fe80::1%lo0 localhost")
-			fmt.Println("ff00::0 ip6-localnet")
-			fmt.Println("ff00::0 ip6-mcastprefix")
-			fmt.Println("ff02::1 ip6-allnodes")
-			fmt.Println("ff02::2 ip6-allrouters")
-			fmt.Println("ff02::3 ip6-allhosts")
-			fmt.Println("0.0.0.0 0.0.0.0")
-			fmt.Println("")
+	if diffMode {
+		diff(hf1, hf2)
+	} else if coverage {
+		cover(hf1, hf2)
+	} else if delta {
+		deltas(hf1, hf2)
+	} else if level != string(hosts.ExactLevel) {
+		compareAt(hf1, hf2)
+	} else if len(outputFormat) > 0 && outputFormat != "text" {
+		compare(hf1, hf2)
+	} else {
+		w := create()
+		defer w.Close()
+		intersection := hf2.Intersection(hf1)
+		if intersectionList {
+			// for now, unceremoniously dump the intersecting domains.
+			fmt.Fprintln(w, "intersection:", intersection)
 		}
+		fmt.Fprintln(w, "Intersection:", humanize.Comma(int64(len(intersection))), "domains")
 
-		prefix := ipLocalhost
-		for i := range slc {
-			if plainOutput {
-				fmt.Println(slc[i])
-			} else {
-				fmt.Println(prefix, slc[i])
-			}
+		if uniquelist {
+			unique := hf2.Unique(hf1)
+			fmt.Fprintln(w, strings.Repeat("-", 40))
+			fmt.Fprintln(w, "Unique in comparison list — ", humanize.Comma(int64(len(unique))), "domains", unique)
 		}
 	}
-	return slc
 }
 
-// Load (generically) a list of hosts into the Hosts struc
-func (h *Hosts) Load(location string) int {
-	// a wrapper to provide a clean loading interface
-	clean := strings.ToLower(location)
-	if strings.HasPrefix(clean, "http") {
-		return h.loadURL(location)
+// listShortcuts prints the shortcut registry as an aligned table.
+func listShortcuts(shortcuts hosts.Shortcuts) {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCATEGORY\tDESCRIPTION\tURL")
+	for _, sc := range shortcuts.Sorted() {
+		fmt.Fprintln(w, sc.Name+"\t"+sc.Category+"\t"+sc.Description+"\t"+sc.URL)
 	}
-	return h.Loadfile(location)
+	w.Flush()
 }
 
-// Load a file of hosts into the Hosts struc
-func (h *Hosts) Loadfile(file string) int {
-	// loading hosts from the file system
-	h.Reset()
-	bytes, err := ioutil.ReadFile(file)
-	h.checkError(err)
-	h.Location = file
-	h.Raw = bytes
-	h.process()
-	return len(bytes)
+// shortcutHelp describes the shortcuts for the -c flag's help text, by category.
+func shortcutHelp(shortcuts hosts.Shortcuts) string {
+	titles := map[string]string{
+		hosts.Amalgamated: "Amalgamated lists' shortcuts:",
+		hosts.SourceList:  "Source lists' shortcuts:",
+	}
+	var help []string
+	category := ""
+	for _, sc := range shortcuts.Sorted() {
+		if sc.Category != category {
+			category = sc.Category
+			title, ok := titles[category]
+			if !ok {
+				title = strings.ToUpper(category[:1]) + category[1:] + " shortcuts:"
+			}
+			help = append(help, "", title)
+		}
+		help = append(help, fmt.Sprintf("-c %-22s // %s", sc.Name, sc.Description))
+	}
+	return strings.Join(help, "\n") + "\n"
 }
 
-// Load hosts into the Hosts struc from a URL
-func (h *Hosts) loadURL(url string) int {
-	// loading hosts from a url
-	h.Reset()
-	var client = http.Client{
-		Timeout: time.Duration(5000 * time.Millisecond),
+// survey reports the health of every shortcut's source, and remembers the
+// domain counts for the next survey to compare against.
+func survey(ctx context.Context, shortcuts hosts.Shortcuts) {
+	// survey each URL once, under its first name
+	var unique []hosts.Shortcut
+	seen := map[string]bool{}
+	for _, sc := range shortcuts.Sorted() {
+		if !seen[sc.URL] {
+			seen[sc.URL] = true
+			unique = append(unique, sc)
+		}
 	}
-	resp, err := client.Get(url)
-	h.checkError(err)
 
-	defer resp.Body.Close()
+	path := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		path = filepath.Join(dir, "ghosts", "survey.json")
+	}
+	previous, err := hosts.ReadSurveyCounts(path)
+	if err != nil {
+		previous = map[string]int{}
+	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	h.checkError(err)
+	results := hosts.Survey(ctx, unique, previous, 8)
+	checkError(hosts.WriteSurvey(stdout, results, time.Now()))
+	checkError(ctx.Err())
 
-	h.Location = url
-	h.Raw = body
-	h.process()
-	return len(body)
+	if len(path) > 0 && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		checkError(hosts.WriteSurveyCounts(path, results))
+	}
 }
 
-// Load hosts from the clipboard
-func (h *Hosts) LoadClipboard(clip string) int {
-	// loading hosts from the file system
-	h.Reset()
-	bytes := []byte(clip)
-	h.Location = "clipboard"
-	h.Raw = bytes
-	h.process()
-	return len(bytes)
+// build writes an amalgamated list built from a local data/ tree.
+func build(ctx context.Context) {
+	var extensions []string
+	for _, ext := range strings.Split(buildExtensions, ",") {
+		if ext = strings.TrimSpace(ext); len(ext) > 0 {
+			extensions = append(extensions, ext)
+		}
+	}
+	h, err := hosts.Build(ctx, buildRoot, hosts.BuildOptions{
+		Extensions: extensions,
+		Whitelist:  whitelistPath,
+		Options:    hosts.Options{Sort: alphaSort, TLD: tld, Root: root},
+	})
+	checkError(err)
+
+	w := create()
+	defer w.Close()
+	checkError(h.Output(w, hosts.OutputOptions{
+		IP:       ipLocalhost,
+		Plain:    plainOutput,
+		NoHeader: noheader,
+		Defaults: addDefaults,
+	}))
+	if len(outPath) > 0 && stats {
+		fmt.Fprintln(stdout, h.Summary("Built hosts file"))
+	}
 }
 
-func (h Hosts) length() int {
-	return len(h.Domains)
+// readme writes the statistics table of the base list and its extension
+// combinations, downloaded, or built from the -build tree.
+func readme(ctx context.Context) {
+	codes := hosts.VariantCodes()
+	stats := make([]hosts.VariantStat, len(codes))
+	errs := make([]error, len(codes))
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		wg.Add(1)
+		go func(i int, code string) {
+			defer wg.Done()
+			h := hosts.New(hosts.Options{})
+			if len(buildRoot) > 0 {
+				h, errs[i] = hosts.Build(ctx, buildRoot, hosts.BuildOptions{Extensions: hosts.VariantExtensions(code)})
+			} else {
+				errs[i] = h.LoadSource(ctx, mustSource(code))
+			}
+			if errs[i] == nil {
+				stats[i] = hosts.VariantStat{Code: code, Domains: len(h.Domains()), Bytes: len(h.Raw())}
+			}
+		}(i, code)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		checkError(err)
+	}
+
+	w := create()
+	defer w.Close()
+	checkError(hosts.WriteStatsTable(w, stats))
 }
 
-func (h Hosts) filter(vs []string, f func(string) bool) []string {
-	vsf := make([]string, 0)
-	for _, v := range vs {
-		if f(v) {
-			vsf = append(vsf, v)
-		}
+// history writes the domain-count timeline of a file in a local git repository.
+func history(ctx context.Context) {
+	g, err := hosts.ParseGitSource(historyLocation)
+	checkError(err)
+	points, err := hosts.History(ctx, g)
+	checkError(err)
+
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "csv":
+		checkError(hosts.WriteHistoryCSV(w, points))
+	case "json":
+		checkError(hosts.WriteHistoryJSON(w, points))
+	default:
+		checkError(usagef("-format %s: want csv or json", outputFormat))
 	}
-	return vsf
 }
 
-func (h Hosts) notEmpty(s string) bool {
-	return len(s) > 0
+// blame writes the commits that added the -blame domain to the -m list, or
+// removed it.
+func blame(ctx context.Context) {
+	g, err := hosts.ParseGitSource(mainHosts)
+	checkError(err)
+	events, err := hosts.Blame(ctx, g, blameDomain)
+	checkError(err)
+
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(hosts.WriteBlame(w, blameDomain, events))
+	case "json":
+		checkError(hosts.WriteBlameJSON(w, blameDomain, events))
+	default:
+		checkError(usagef("-format %s: want text or json", outputFormat))
+	}
 }
 
-func (h Hosts) notComment(s string) bool {
-	return !strings.HasPrefix(s, "#")
+// diff writes the domains only in the main list, only in the compared list,
+// and, with -intersection, in both.
+func diff(hf1, hf2 *hosts.Hosts) {
+	d := hf1.Diff(hf2)
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(d.Write(w, intersectionList))
+	case "json":
+		checkError(d.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text or json", outputFormat))
+	}
 }
 
-func (h Hosts) scrub(s string, r string) string {
-	return strings.ReplaceAll(s, r, "")
+// compare writes how the main and compared lists overlap in a
+// machine-readable -format. JSON holds every part, with its count; lines
+// and CSV hold every domain of either list or, with -intersection or
+// -unique, only the domains of those parts.
+func compare(hf1, hf2 *hosts.Hosts) {
+	d := hf1.Diff(hf2)
+	selected := d
+	if intersectionList || uniquelist {
+		selected = d.Select(false, uniquelist, intersectionList)
+	}
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "lines":
+		checkError(selected.WriteLines(w))
+	case "csv":
+		checkError(selected.WriteCSV(w))
+	case "json":
+		checkError(d.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text, lines, csv, or json", outputFormat))
+	}
 }
 
-func (h Hosts) replace(s string, r string, n string) string {
-	return strings.ReplaceAll(s, r, n)
+// compareAt writes the roots or TLDs that the main and compared lists share.
+func compareAt(hf1, hf2 *hosts.Hosts) {
+	c := hf1.CompareAt(hf2, hosts.Level(level))
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(c.Write(w, top))
+	case "csv":
+		checkError(c.WriteCSV(w))
+	case "json":
+		checkError(c.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text, csv, or json", outputFormat))
+	}
 }
 
-func (h Hosts) checkError(err error) {
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+// deltas writes how the TLD and root domain tallies change from the main
+// list to the compared list.
+func deltas(hf1, hf2 *hosts.Hosts) {
+	kinds := map[string][]hosts.TallyDelta{}
+	if tld || !root {
+		kinds["tld"] = hf1.TLDDeltas(hf2)
+	}
+	if root || !tld {
+		kinds["root"] = hf1.RootDeltas(hf2)
+	}
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		if d, ok := kinds["tld"]; ok {
+			checkError(hosts.WriteTallyDeltas(w, "TLD tally", d, top))
+		}
+		if d, ok := kinds["root"]; ok {
+			if _, both := kinds["tld"]; both {
+				fmt.Fprintln(w)
+			}
+			checkError(hosts.WriteTallyDeltas(w, "Root domain tally", d, top))
+		}
+	case "csv":
+		header := true
+		for _, kind := range []string{"tld", "root"} {
+			if d, ok := kinds[kind]; ok {
+				checkError(hosts.WriteTallyDeltasCSV(w, kind, d, header))
+				header = false
+			}
+		}
+	case "json":
+		checkError(hosts.WriteTallyDeltasJSON(w, kinds))
+	default:
+		checkError(usagef("-format %s: want text, csv, or json", outputFormat))
 	}
 }
 
-// Structure and functions for custom domain sorting.
-type domainSort []string
-
-func (s domainSort) Len() int {
-	return len(s)
-}
-func (s domainSort) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
+// cover writes how the main list covers the compared list, exactly or
+// through parent domains.
+func cover(hf1, hf2 *hosts.Hosts) {
+	c := hf1.Coverage(hf2)
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(c.Write(w, intersectionList))
+	case "json":
+		checkError(c.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text or json", outputFormat))
+	}
 }
 
-func (s domainSort) Less(i, j int) bool {
-	hf := Hosts{}
-	return hf.Normalize(s[i]) < hf.Normalize(s[j])
+// overlap writes how much each pair of lists have in common.
+func overlap(ctx context.Context, locations []string) {
+	if len(locations) < 2 {
+		checkError(usagef("-matrix: want two or more lists after the flags"))
+	}
+	o := hosts.NewOverlap(locations, loadAll(ctx, locations))
+
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(o.Write(w))
+	case "csv":
+		checkError(o.WriteCSV(w))
+	case "json":
+		checkError(o.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text, csv, or json", outputFormat))
+	}
 }
 
-// Normalize the host string for sorting
-func (h *Hosts) Normalize(c string) string {
-	pad := " "
-	length := 50
-	cslice := strings.Split(c, ".")
-	parts := len(cslice)
-	out := c
-	if parts > 1 {
-		out = padRight(cslice[parts-2], length, pad)
-		out += padRight(cslice[parts-1], length, pad)
-		reverseSlice := reverse(cslice)
-		if parts > 2 {
-			slc := reverseSlice[2:]
-			for i := range slc {
-				out += padRight(slc[i], length, " ")
-			}
-		}
+// contribution writes what each list has that no other has, and how the
+// domains of the lists are shared among them.
+func contribution(ctx context.Context, locations []string) {
+	if len(locations) < 2 {
+		checkError(usagef("-upset: want two or more lists after the flags"))
+	}
+	c := hosts.NewContribution(locations, loadAll(ctx, locations))
+
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(c.Write(w, top))
+	case "json":
+		checkError(c.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text or json", outputFormat))
 	}
-	return out
 }
 
-func times(str string, n int) string {
-	if n <= 0 {
-		return ""
+// reconciliation writes the hand additions to the -m list, and the domains
+// of its sources it dropped.
+func reconciliation(ctx context.Context, locations []string) {
+	if len(locations) == 0 {
+		checkError(usagef("-reconcile: want the sources of the -m list after the flags"))
+	}
+	final := hosts.New(hosts.Options{})
+	checkError(load(ctx, final, mainHosts))
+	pin(final)
+	r := hosts.Reconcile(final, loadAll(ctx, locations))
+
+	w := create()
+	defer w.Close()
+	switch outputFormat {
+	case "", "text":
+		checkError(r.Write(w))
+	case "json":
+		checkError(r.WriteJSON(w))
+	default:
+		checkError(usagef("-format %s: want text or json", outputFormat))
 	}
-	return strings.Repeat(str, n)
 }
 
-func padRight(str string, length int, pad string) string {
-	return str + times(pad, length-len(str))
+// loadAll loads lists concurrently, and pins them. It records every list
+// that loaded as finished before it exits on the first error.
+func loadAll(ctx context.Context, locations []string) []*hosts.Hosts {
+	lists := make([]*hosts.Hosts, len(locations))
+	errs := make([]error, len(locations))
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		wg.Add(1)
+		go func(i int, location string) {
+			defer wg.Done()
+			lists[i] = hosts.New(hosts.Options{})
+			errs[i] = lists[i].LoadSource(ctx, mustSource(location))
+		}(i, location)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err == nil {
+			finished = append(finished, lists[i])
+		}
+	}
+	for _, err := range errs {
+		checkError(err)
+	}
+	for _, h := range lists {
+		pin(h)
+	}
+	return lists
 }
 
-func reverse(a []string) []string {
-	for i := len(a)/2 - 1; i >= 0; i-- {
-		opp := len(a) - 1 - i
-		a[i], a[opp] = a[opp], a[i]
+// create returns the -out file, or stdout. The file is created once per run,
+// so every result of the run lands in it, and done closes it. With -copy,
+// what is written to the file is copied to the clipboard too.
+func create() io.WriteCloser {
+	if len(outPath) == 0 {
+		return nopCloser{stdout}
+	}
+	if outFile == nil {
+		f, err := os.Create(outPath)
+		checkError(err)
+		outFile = f
+		out = f
+		if copyResult {
+			out = io.MultiWriter(f, &copied)
+		}
 	}
-	return a
+	return nopCloser{out}
 }
 
-func FlagSet() {
-	defaultMainHosts := "base"
-	flag.StringVar(&compareHosts, "c", "", `Hosts list to compare.
-A shortcut code, full URL, or a local file.
-Use the -m option for the main comparison list.
-Use the -clip option to use what is on the system clipboard.
+// outFile is the -out file, once created, and out writes to it.
+var outFile *os.File
+var out io.Writer
 
-Shortcut codes
-==============
-The following shortcut codes can be used to select among preset main lists.
+type nopCloser struct{ io.Writer }
 
-Amalgamated lists' shortcuts:
--c b or -m base // use Steven Black's base amalgamated list.
--c f    // use alternates/fakenews/hosts
--c fg   // use alternates/fakenews-gambling/hosts
--c fgp  // use alternates/fakenews-gambling-porn/hosts
--c fgps // use alternates/fakenews-gambling-porn-social/hosts
--c fgs  // use alternates/fakenews-gambling-social/hosts
--c fp   // use alternates/fakenews-porn/hosts
--c fps  // use alternates/fakenews-porn-social/hosts
--c fs   // use alternates/fakenews-social/hosts
--c g    // use alternates/gambling/hosts
--c gp   // use alternates/gambling-porn/hosts
--c gps  // use alternates/gambling-porn-social/hosts
--c gs   // use alternates/gambling-social/hosts
--c p    // use alternates/porn/hosts
--c ps   // use alternates/porn-social/hosts
--c s    // use alternates/social/hosts
-
-Source lists' shortcuts:
--c adaway                // adaway.github.io
--c add2o7net             // FadeMind add.2o7Net hosts
--c adddead               // FadeMind add.Dead hosts
--c addrisk               // FadeMind add.Risk hosts
--c addspam               // FadeMind add.Spam hosts
--c adguard               // AdguardTeam cname-trackers
--c baddboyz              // mitchellkrogza Badd-Boyz-Hosts
--c clefspear             // Clefspeare13 pornhosts
--c digitalside           // davidonzo Threat-Intel
--c fakenews              // marktron/fakenews
--c hostsvn               // bigdargon hostsVN
--c kadhosts              // PolishFiltersTeam
--c metamask              // MetaMask eth-phishing hosts
--c mvps                  // winhelp2002.mvps.or
--c orca                  // orca.pet notonmyshift hosts
--c shady                 // hreyasminocha shady hosts
--c sinfonietta-gambling
--c sinfonietta-porn
--c sinfonietta-snuff
--c sinfonietta-social
--c someonewhocares       // Sam Pollock someonewhocares.org
--c stevenblack           // Steven Black ad-hoc list
--c tiuxo-porn
--c tiuxo-social
--c tiuxo                 // tiuxo list.
--c uncheckyads           // FadeMind  UncheckyAds
--c urlhaus               // urlhaus.abuse.ch
--c yoyo                  // Peter Lowe yoyo.org
-`)
-	flag.BoolVar(&sysclipboard, "clip", false, "The comparison hosts are in the system clipboard")
-	flag.BoolVar(&addDefaults, "d", false, "Include default hosts at the top of file.")
-	flag.BoolVar(&intersectionList, "intersection", false, "Return the list of intersection hosts? (default false)")
-	flag.BoolVar(&uniquelist, "unique", false, "List the unique domains in the comparison list")
-	flag.StringVar(&ipLocalhost, "ip", "0.0.0.0", "Localhost IP address")
-	flag.StringVar(&mainHosts, "m", defaultMainHosts, `The main list of hosts to analyze, or serve as a basis for comparison.
-A shortcut code, a full URL, or a local file.
-See the -c flag for the list of shortcut codes.`)
-	flag.BoolVar(&noheader, "noheader", false, "Remove the file header from output? (default false)")
-	flag.BoolVar(&output, "o", false, "Return the list of hosts? (default false)")
-	flag.BoolVar(&plainOutput, "p", false, "Return a plain output list of hosts, with no IP address prefix? (default false)")
-	flag.BoolVar(&alphaSort, "s", false, "Sort the hosts? (default false)")
-	flag.BoolVar(&stats, "stats", true, "display stats?")
-	flag.BoolVar(&tld, "tld", false, "Return the list of TLD and their tally (default false)")
-	flag.BoolVar(&root, "root", false, "Return the list of root domains and their tally (default false)")
-	flag.BoolVar(&version, "v", false, "Return the current version")
-	flag.Parse()
-}
+func (nopCloser) Close() error { return nil }
 
-func main() {
+// stdout is where the results go: standard output and, with -copy, the
+// clipboard too.
+var stdout io.Writer = os.Stdout
 
-	FlagSet()
+// copied holds the results to copy to the clipboard, with -copy.
+var copied bytes.Buffer
 
-	hf1 := Hosts{}
-	listShortcuts := map[string]string{
-		"b":                    "https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts",
-		"base":                 "https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts",
-		"f":                    "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews/hosts",
-		"fg":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-gambling/hosts",
-		"fgp":                  "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-gambling-porn/hosts",
-		"fgps":                 "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-gambling-porn-social/hosts",
-		"fgs":                  "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-gambling-social/hosts",
-		"fp":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-porn/hosts",
-		"fps":                  "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-porn-social/hosts",
-		"fs":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/fakenews-social/hosts",
-		"g":                    "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/gambling/hosts",
-		"gp":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/gambling-porn/hosts",
-		"gps":                  "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/gambling-porn-social/hosts",
-		"gs":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/gambling-social/hosts",
-		"p":                    "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/porn/hosts",
-		"ps":                   "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/porn-social/hosts",
-		"s":                    "https://raw.githubusercontent.com/StevenBlack/hosts/master/alternates/social/hosts",
-		"adaway":               "https://raw.githubusercontent.com/AdAway/adaway.github.io/master/hosts.txt",
-		"add2o7net":            "https://raw.githubusercontent.com/FadeMind/hosts.extras/master/add.2o7Net/hosts",
-		"adddead":              "https://raw.githubusercontent.com/FadeMind/hosts.extras/master/add.Dead/hosts",
-		"addrisk":              "https://raw.githubusercontent.com/FadeMind/hosts.extras/master/add.Risk/hosts",
-		"addspam":              "https://raw.githubusercontent.com/FadeMind/hosts.extras/master/add.Spam/hosts",
-		"adguard":              "https://raw.githubusercontent.com/AdguardTeam/cname-trackers/master/combined_disguised_trackers_justdomains.txt",
-		"baddboyz":             "https://raw.githubusercontent.com/mitchellkrogza/Badd-Boyz-Hosts/master/hosts",
-		"clefspear":            "https://raw.githubusercontent.com/Clefspeare13/pornhosts/master/0.0.0.0/hosts",
-		"digitalside":          "https://raw.githubusercontent.com/davidonzo/Threat-Intel/master/lists/latestdomains.piHole.txt",
-		"fakenews":             "https://raw.githubusercontent.com/marktron/fakenews/master/fakenews",
-		"hostsvn":              "https://raw.githubusercontent.com/bigdargon/hostsVN/master/option/hosts-VN",
-		"kadhosts":             "https://raw.githubusercontent.com/PolishFiltersTeam/KADhosts/master/KADhosts.txt",
-		"metamask":             "https://raw.githubusercontent.com/MetaMask/eth-phishing-detect/master/src/hosts.txt",
-		"mvps":                 "https://winhelp2002.mvps.org/hosts.txt",
-		"orca":                 "https://orca.pet/notonmyshift/hosts.txt",
-		"shady":                "https://raw.githubusercontent.com/shreyasminocha/shady-hosts/main/hosts",
-		"sinfonietta-gambling": "https://raw.githubusercontent.com/Sinfonietta/hostfiles/master/gambling-hosts",
-		"sinfonietta-porn":     "https://raw.githubusercontent.com/Sinfonietta/hostfiles/master/pornography-hosts",
-		"sinfonietta-snuff":    "https://raw.githubusercontent.com/Sinfonietta/hostfiles/master/snuff-hosts",
-		"sinfonietta-social":   "https://raw.githubusercontent.com/Sinfonietta/hostfiles/master/social-hosts",
-		"someonewhocares":      "https://someonewhocares.org/hosts/zero/hosts",
-		"stevenblack":          "https://raw.githubusercontent.com/StevenBlack/hosts/master/data/StevenBlack/hosts",
-		"tiuxo-porn":           "https://raw.githubusercontent.com/tiuxo/hosts/master/porn",
-		"tiuxo-social":         "https://raw.githubusercontent.com/tiuxo/hosts/master/social",
-		"tiuxo":                "https://raw.githubusercontent.com/tiuxo/hosts/master/ads",
-		"uncheckyads":          "https://raw.githubusercontent.com/FadeMind/hosts.extras/master/UncheckyAds/hosts",
-		"urlhaus":              "https://urlhaus.abuse.ch/downloads/hostfile/",
-		"yoyo":                 "https://pgl.yoyo.org/adservers/serverlist.php?hostformat=hosts&mimetype=plaintext&useip=0.0.0.0",
-	}
-
-	_, shortCode := listShortcuts[mainHosts]
-	if shortCode {
-		mainHosts = listShortcuts[mainHosts]
-	}
+// done finishes the run and exits.
+func done() {
+	checkError(finish())
+	os.Exit(ExitOK)
+}
 
-	if version {
-		fmt.Println("The current version is:", VERSION)
-		os.Exit(0)
+// finish saves the lockfile, with -updatelock, closes the -out file, and
+// copies the results to the clipboard, with -copy.
+func finish() error {
+	if updateLock && lock != nil {
+		if err := lock.Write(); err != nil {
+			return err
+		}
+	}
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			return err
+		}
 	}
+	if copyResult {
+		if err := writeClipboard(copied.String()); err != nil {
+			return fmt.Errorf("-copy: %w", err)
+		}
+	}
+	return nil
+}
 
-	hf1.Load(mainHosts)
+// writeClipboard puts text on the system clipboard.
+var writeClipboard = clipboard.WriteAll
 
-	if stats && !output {
-		fmt.Println(hf1.Summary("Base hosts file"))
-	}
+// mustSource returns the Source of a location, or exits.
+func mustSource(location string) hosts.Source {
+	src, err := shortcuts.Source(location)
+	checkError(err)
+	return src
+}
 
-	if len(compareHosts) > 0 {
-		_, shortCode := listShortcuts[compareHosts]
-		if shortCode {
-			compareHosts = listShortcuts[compareHosts]
-		}
+// shortcuts is the resolved shortcut registry.
+var shortcuts hosts.Shortcuts
 
-		hf2 := Hosts{}
-		hf2.Load(compareHosts)
-		if stats && !output {
-			fmt.Println(hf2.Summary("Compared hosts file"))
-		}
+// finished records the sources loaded so far, for the partial summary
+// printed when a run is interrupted.
+var finished []*hosts.Hosts
 
-		hf2.Intersection = funk.IntersectString(hf1.Domains, hf2.Domains)
-		if intersectionList {
-			// for now, unceremoniously dump the intersecting domains.
-			fmt.Println("intersection:", hf2.Intersection)
-		}
-		fmt.Println("Intersection:", humanize.Comma(int64(len(hf2.Intersection))), "domains")
+// load a hosts list, by shortcut or location, and record it as finished.
+// Shortcuts with mirrors fall back to them.
+func load(ctx context.Context, h *hosts.Hosts, location string) error {
+	src, err := shortcuts.Source(location)
+	if err != nil {
+		return err
+	}
+	if err := h.LoadSource(ctx, src); err != nil {
+		return err
+	}
+	finished = append(finished, h)
+	return nil
+}
 
-		if uniquelist {
-			_, hf2.Unique = funk.DifferenceString(hf2.Intersection, hf2.Domains)
-			fmt.Println(strings.Repeat("-", 40))
-			fmt.Println("Unique in comparison list — ", humanize.Comma(int64(len(hf2.Unique))), "domains", hf2.Unique)
-		}
-	} else if sysclipboard {
-		hf2 := Hosts{}
-		clip, _ := clipboard.ReadAll()
-		hf2.LoadClipboard(clip)
-		if stats && !output {
-			fmt.Println(hf2.Summary("Compared hosts from clipboard"))
-		}
+// checkError reports a fatal error and exits with the code of its class.
+// An interrupted run first reports which sources finished.
+func checkError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+	code := exitCode(err)
+	if code == ExitCanceled {
+		fmt.Fprintln(os.Stderr, partialSummary())
+	}
+	os.Exit(code)
+}
 
-		hf2.Intersection = funk.IntersectString(hf1.Domains, hf2.Domains)
+// partialSummary describes the sources that finished before an interruption.
+func partialSummary() string {
+	summary := []string{"Interrupted after " + humanize.Comma(int64(len(finished))) + " finished sources."}
+	for _, h := range finished {
+		summary = append(summary, "   "+h.Location()+": "+humanize.Comma(int64(len(h.Domains())))+" domains")
+	}
+	return strings.Join(summary, "\n")
+}
 
-		if intersectionList {
-			// for now, unceremoniously dump the intersecting domains.
-			fmt.Println("intersection:", hf2.Intersection)
-		}
-		fmt.Println("Intersection:", humanize.Comma(int64(len(hf2.Intersection))), "domains")
+// write outputs a loaded hosts list when the -o flag is set.
+func write(h *hosts.Hosts) {
+	if !output {
+		return
+	}
+	w := create()
+	defer w.Close()
+	checkError(h.Output(w, hosts.OutputOptions{
+		IP:       ipLocalhost,
+		Plain:    plainOutput,
+		NoHeader: noheader,
+		Defaults: addDefaults,
+	}))
+}
 
-		if uniquelist {
-			_, hf2.Unique = funk.DifferenceString(hf2.Intersection, hf2.Domains)
-			fmt.Println("unique in comparison list:", hf2.Unique)
-		}
+// lock is the lockfile, read when -verify or -updatelock is set.
+var lock *hosts.Lockfile
+
+// pin verifies, or refreshes, the lockfile pin of a loaded hosts list.
+func pin(h *hosts.Hosts) {
+	if lock == nil {
+		return
+	}
+	if updateLock {
+		fmt.Fprintln(stdout, lock.Update(h))
+	} else if verifyLock {
+		checkError(lock.Verify(h))
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StevenBlack/ghosts/hosts"
+)
+
+// copyRun sets up a comparison of the -m list with -copy, capturing stdout
+// and the clipboard, and restores the flags when the test ends.
+func copyRun(t *testing.T, compare, path string, clip bool) (*bytes.Buffer, *string) {
+	c, o, sc, cr, u, l, s, wc := compareHosts, outPath, sysclipboard, copyResult, uniquelist, level, stdout, writeClipboard
+	t.Cleanup(func() {
+		compareHosts, outPath, sysclipboard, copyResult, uniquelist, level, stdout, writeClipboard = c, o, sc, cr, u, l, s, wc
+		outFile, out = nil, nil
+		copied.Reset()
+	})
+
+	compareHosts, outPath, sysclipboard, copyResult, uniquelist, level = compare, path, clip, true, true, string(hosts.ExactLevel)
+	var buf bytes.Buffer
+	stdout = io.MultiWriter(&buf, &copied)
+	clipboard := new(string)
+	writeClipboard = func(text string) error {
+		*clipboard = text
+		return nil
+	}
+	return &buf, clipboard
+}
+
+// compareCopied runs the comparison of the -m list, as main does.
+func compareCopied(t *testing.T) {
+	hf1 := hosts.New(hosts.Options{})
+	if err := hf1.Load(context.Background(), "test/hosts-main"); err != nil {
+		t.Fatal(err)
+	}
+	if len(compareHosts) == 0 && sysclipboard {
+		compareHosts = "clip:"
+	}
+	comparison(context.Background(), hosts.Options{}, hf1, true)
+	if err := finish(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyComparison(t *testing.T) {
+	// testing that -copy puts on the clipboard what the comparison printed
+	buf, clipboard := copyRun(t, "test/hosts-multi", "", false)
+	compareCopied(t)
+
+	if !strings.Contains(buf.String(), "Compared hosts file") || !strings.Contains(buf.String(), "Unique in comparison list") {
+		t.Errorf("got output %q, want a summary and the unique domains", buf.String())
+	}
+	if *clipboard != buf.String() {
+		t.Errorf("got clipboard %q, want %q", *clipboard, buf.String())
+	}
+}
+
+func TestCopyClipComparison(t *testing.T) {
+	// testing that -clip compares with the clipboard, and -copy copies the result
+	hosts.Register("clip", func(location string) (hosts.Source, error) {
+		return hosts.FileSource("test/hosts-multi"), nil
+	})
+	defer hosts.Register("clip", func(location string) (hosts.Source, error) { return hosts.ClipboardSource{}, nil })
+
+	buf, clipboard := copyRun(t, "", "", true)
+	compareCopied(t)
+
+	if !strings.Contains(buf.String(), "Compared hosts from clipboard") {
+		t.Errorf("got output %q, want the clipboard summary", buf.String())
+	}
+	if *clipboard != buf.String() {
+		t.Errorf("got clipboard %q, want %q", *clipboard, buf.String())
+	}
+}
+
+func TestCopyOut(t *testing.T) {
+	// testing that -copy with -out copies what is written to the file
+	path := filepath.Join(t.TempDir(), "out.txt")
+	buf, clipboard := copyRun(t, "test/hosts-multi", path, false)
+	compareCopied(t)
+
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(file), "Unique in comparison list") {
+		t.Errorf("got file %q, want the unique domains", file)
+	}
+	if strings.Contains(buf.String(), "Unique in comparison list") {
+		t.Errorf("got the comparison on stdout, want it in the -out file")
+	}
+	if !strings.HasSuffix(*clipboard, string(file)) {
+		t.Errorf("got clipboard %q, want it to end with %q", *clipboard, file)
+	}
+}